@@ -0,0 +1,63 @@
+package collection
+
+import (
+	"fmt"
+	"strings"
+)
+
+// format renders a value nested inside a collection. Strings are quoted so
+// ["1"] and [1] can be told apart; collections already being printed show
+// up as [...] or {...} instead of recursing forever.
+func format(value any, seen map[any]bool) string {
+	switch v := value.(type) {
+	case nil:
+		return "nil"
+	case float64:
+		s := fmt.Sprintf("%f", v)
+		s = strings.TrimRight(s, "0")
+		s = strings.TrimRight(s, ".")
+		return s
+	case string:
+		return `"` + v + `"`
+	case []rune:
+		return `"` + string(v) + `"`
+	case *List:
+		if seen[v] {
+			return "[...]"
+		}
+		seen[v] = true
+		defer delete(seen, v)
+
+		var b strings.Builder
+		b.WriteString("[")
+		for i, element := range v.Elements {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(format(element, seen))
+		}
+		b.WriteString("]")
+		return b.String()
+	case *Map:
+		if seen[v] {
+			return "{...}"
+		}
+		seen[v] = true
+		defer delete(seen, v)
+
+		var b strings.Builder
+		b.WriteString("{")
+		for i, e := range v.entries {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(format(e.key, seen))
+			b.WriteString(": ")
+			b.WriteString(format(e.value, seen))
+		}
+		b.WriteString("}")
+		return b.String()
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -0,0 +1,164 @@
+package collection
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+// List is the Lox list value, shared by the tree-walking interpreter and
+// the bytecode VM. Errors returned by its operations carry only a message;
+// each engine reports them at the token of the failing expression.
+type List struct {
+	Elements []any
+}
+
+func NewList(elements []any) *List {
+	return &List{
+		Elements: elements,
+	}
+}
+
+func (l *List) Len() int {
+	return len(l.Elements)
+}
+
+// index checks that i is an integer number in [0, limit).
+func index(i any, limit int) (int, error) {
+	n, ok := i.(float64)
+	if !ok {
+		return 0, errors.New("List index must be a number.")
+	}
+	if n != math.Trunc(n) {
+		return 0, errors.New("List index must be an integer.")
+	}
+	if n < 0 || n >= float64(limit) {
+		return 0, errors.New("List index out of range.")
+	}
+	return int(n), nil
+}
+
+func (l *List) Get(i any) (any, error) {
+	n, e := index(i, len(l.Elements))
+	if e != nil {
+		return nil, e
+	}
+	return l.Elements[n], nil
+}
+
+func (l *List) Set(i any, value any) error {
+	n, e := index(i, len(l.Elements))
+	if e != nil {
+		return e
+	}
+	l.Elements[n] = value
+	return nil
+}
+
+func (l *List) push(args []any) (any, error) {
+	l.Elements = append(l.Elements, args[0])
+	return nil, nil
+}
+
+func (l *List) pop(args []any) (any, error) {
+	if len(l.Elements) == 0 {
+		return nil, errors.New("Can't pop from an empty list.")
+	}
+	last := l.Elements[len(l.Elements)-1]
+	l.Elements = l.Elements[:len(l.Elements)-1]
+	return last, nil
+}
+
+func (l *List) insert(args []any) (any, error) {
+	// Inserting at len(l) appends.
+	n, e := index(args[0], len(l.Elements)+1)
+	if e != nil {
+		return nil, e
+	}
+	l.Elements = append(l.Elements, nil)
+	copy(l.Elements[n+1:], l.Elements[n:])
+	l.Elements[n] = args[1]
+	return nil, nil
+}
+
+func (l *List) remove(args []any) (any, error) {
+	n, e := index(args[0], len(l.Elements))
+	if e != nil {
+		return nil, e
+	}
+	removed := l.Elements[n]
+	l.Elements = append(l.Elements[:n], l.Elements[n+1:]...)
+	return removed, nil
+}
+
+func (l *List) slice(args []any) (any, error) {
+	start, e := index(args[0], len(l.Elements)+1)
+	if e != nil {
+		return nil, e
+	}
+	end, e := index(args[1], len(l.Elements)+1)
+	if e != nil {
+		return nil, e
+	}
+	if start > end {
+		return nil, errors.New("Slice start is after its end.")
+	}
+
+	elements := make([]any, end-start)
+	copy(elements, l.Elements[start:end])
+	return NewList(elements), nil
+}
+
+// join concatenates the elements, printed the way print shows them, with a
+// separator between. The two engines represent strings differently, so
+// the result is the same kind of string as the separator.
+func (l *List) join(args []any) (any, error) {
+	parts := make([]string, len(l.Elements))
+	for i, element := range l.Elements {
+		switch element := element.(type) {
+		case string:
+			parts[i] = element
+		case []rune:
+			parts[i] = string(element)
+		default:
+			parts[i] = format(element, map[any]bool{})
+		}
+	}
+
+	switch sep := args[0].(type) {
+	case string:
+		return strings.Join(parts, sep), nil
+	case []rune:
+		return []rune(strings.Join(parts, string(sep))), nil
+	}
+	return nil, errors.New("Separator must be a string.")
+}
+
+// Method is a built-in method bound to its receiver.
+type Method struct {
+	Name  string
+	Arity int
+	Fn    func(args []any) (any, error)
+}
+
+func (l *List) Method(name string) (Method, bool) {
+	switch name {
+	case "push":
+		return Method{name, 1, l.push}, true
+	case "pop":
+		return Method{name, 0, l.pop}, true
+	case "insert":
+		return Method{name, 2, l.insert}, true
+	case "remove":
+		return Method{name, 1, l.remove}, true
+	case "slice":
+		return Method{name, 2, l.slice}, true
+	case "join":
+		return Method{name, 1, l.join}, true
+	}
+	return Method{}, false
+}
+
+func (l *List) String() string {
+	return format(l, map[any]bool{})
+}
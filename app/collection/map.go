@@ -0,0 +1,126 @@
+package collection
+
+import (
+	"fmt"
+)
+
+type entry struct {
+	key   any
+	value any
+}
+
+// Map is the Lox map value. Entries keep their insertion order, which is
+// the order keys() and values() report them in.
+type Map struct {
+	entries []entry
+	index   map[any]int
+}
+
+func NewMap() *Map {
+	return &Map{
+		entries: make([]entry, 0),
+		index:   make(map[any]int),
+	}
+}
+
+// hashKey returns the Go map key a Lox value is stored under. Two keys hash
+// the same exactly when Lox considers them equal: numbers, strings,
+// booleans and nil by value, everything else by identity. Strings arrive as
+// []rune from the tree-walking interpreter and as string from the VM.
+func hashKey(key any) any {
+	if runes, ok := key.([]rune); ok {
+		return string(runes)
+	}
+	return key
+}
+
+func (m *Map) Len() int {
+	return len(m.entries)
+}
+
+func (m *Map) Has(key any) bool {
+	_, ok := m.index[hashKey(key)]
+	return ok
+}
+
+func (m *Map) Get(key any) (any, error) {
+	i, ok := m.index[hashKey(key)]
+	if !ok {
+		return nil, fmt.Errorf("Undefined key %s.", format(key, map[any]bool{}))
+	}
+	return m.entries[i].value, nil
+}
+
+func (m *Map) Set(key any, value any) {
+	hash := hashKey(key)
+	if i, ok := m.index[hash]; ok {
+		m.entries[i].value = value
+		return
+	}
+	m.index[hash] = len(m.entries)
+	m.entries = append(m.entries, entry{key: key, value: value})
+}
+
+func (m *Map) Delete(key any) bool {
+	hash := hashKey(key)
+	i, ok := m.index[hash]
+	if !ok {
+		return false
+	}
+	delete(m.index, hash)
+	m.entries = append(m.entries[:i], m.entries[i+1:]...)
+	for n := i; n < len(m.entries); n++ {
+		m.index[hashKey(m.entries[n].key)] = n
+	}
+	return true
+}
+
+func (m *Map) Keys() []any {
+	keys := make([]any, len(m.entries))
+	for i, e := range m.entries {
+		keys[i] = e.key
+	}
+	return keys
+}
+
+func (m *Map) Values() []any {
+	values := make([]any, len(m.entries))
+	for i, e := range m.entries {
+		values[i] = e.value
+	}
+	return values
+}
+
+func (m *Map) has(args []any) (any, error) {
+	return m.Has(args[0]), nil
+}
+
+func (m *Map) delete(args []any) (any, error) {
+	return m.Delete(args[0]), nil
+}
+
+func (m *Map) keys(args []any) (any, error) {
+	return NewList(m.Keys()), nil
+}
+
+func (m *Map) values(args []any) (any, error) {
+	return NewList(m.Values()), nil
+}
+
+func (m *Map) Method(name string) (Method, bool) {
+	switch name {
+	case "has":
+		return Method{name, 1, m.has}, true
+	case "delete":
+		return Method{name, 1, m.delete}, true
+	case "keys":
+		return Method{name, 0, m.keys}, true
+	case "values":
+		return Method{name, 0, m.values}, true
+	}
+	return Method{}, false
+}
+
+func (m *Map) String() string {
+	return format(m, map[any]bool{})
+}
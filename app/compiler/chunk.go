@@ -0,0 +1,170 @@
+package compiler
+
+import (
+	"sort"
+
+	tok "github.com/codecrafters-io/interpreter-starter-go/app/token"
+)
+
+type OpCode byte
+
+const (
+	OP_CONSTANT OpCode = iota
+	OP_NIL
+	OP_TRUE
+	OP_FALSE
+	OP_POP
+	OP_GET_LOCAL
+	OP_SET_LOCAL
+	OP_GET_GLOBAL
+	OP_DEFINE_GLOBAL
+	OP_SET_GLOBAL
+	OP_GET_UPVALUE
+	OP_SET_UPVALUE
+	OP_GET_PROPERTY
+	OP_SET_PROPERTY
+	OP_GET_SUPER
+	OP_EQUAL
+	OP_GREATER
+	OP_GREATER_EQUAL
+	OP_LESS
+	OP_LESS_EQUAL
+	OP_ADD
+	OP_SUBTRACT
+	OP_MULTIPLY
+	OP_DIVIDE
+	OP_NOT
+	OP_NEGATE
+	OP_PRINT
+	OP_JUMP
+	OP_JUMP_IF_FALSE
+	OP_LOOP
+	OP_CALL
+	OP_CLOSURE
+	OP_CLOSE_UPVALUE
+	OP_RETURN
+	OP_CLASS
+	OP_INHERIT
+	OP_METHOD
+	OP_BUILD_LIST
+	OP_GET_INDEX
+	OP_SET_INDEX
+	OP_BUILD_MAP
+	OP_TRY
+	OP_END_TRY
+	OP_CATCH
+	OP_THROW
+	OP_IMPORT
+	OP_GET_EXPORT
+)
+
+var opNames = [...]string{
+	OP_CONSTANT:      "OP_CONSTANT",
+	OP_NIL:           "OP_NIL",
+	OP_TRUE:          "OP_TRUE",
+	OP_FALSE:         "OP_FALSE",
+	OP_POP:           "OP_POP",
+	OP_GET_LOCAL:     "OP_GET_LOCAL",
+	OP_SET_LOCAL:     "OP_SET_LOCAL",
+	OP_GET_GLOBAL:    "OP_GET_GLOBAL",
+	OP_DEFINE_GLOBAL: "OP_DEFINE_GLOBAL",
+	OP_SET_GLOBAL:    "OP_SET_GLOBAL",
+	OP_GET_UPVALUE:   "OP_GET_UPVALUE",
+	OP_SET_UPVALUE:   "OP_SET_UPVALUE",
+	OP_GET_PROPERTY:  "OP_GET_PROPERTY",
+	OP_SET_PROPERTY:  "OP_SET_PROPERTY",
+	OP_GET_SUPER:     "OP_GET_SUPER",
+	OP_EQUAL:         "OP_EQUAL",
+	OP_GREATER:       "OP_GREATER",
+	OP_GREATER_EQUAL: "OP_GREATER_EQUAL",
+	OP_LESS:          "OP_LESS",
+	OP_LESS_EQUAL:    "OP_LESS_EQUAL",
+	OP_ADD:           "OP_ADD",
+	OP_SUBTRACT:      "OP_SUBTRACT",
+	OP_MULTIPLY:      "OP_MULTIPLY",
+	OP_DIVIDE:        "OP_DIVIDE",
+	OP_NOT:           "OP_NOT",
+	OP_NEGATE:        "OP_NEGATE",
+	OP_PRINT:         "OP_PRINT",
+	OP_JUMP:          "OP_JUMP",
+	OP_JUMP_IF_FALSE: "OP_JUMP_IF_FALSE",
+	OP_LOOP:          "OP_LOOP",
+	OP_CALL:          "OP_CALL",
+	OP_CLOSURE:       "OP_CLOSURE",
+	OP_CLOSE_UPVALUE: "OP_CLOSE_UPVALUE",
+	OP_RETURN:        "OP_RETURN",
+	OP_CLASS:         "OP_CLASS",
+	OP_INHERIT:       "OP_INHERIT",
+	OP_METHOD:        "OP_METHOD",
+	OP_BUILD_LIST:    "OP_BUILD_LIST",
+	OP_GET_INDEX:     "OP_GET_INDEX",
+	OP_SET_INDEX:     "OP_SET_INDEX",
+	OP_BUILD_MAP:     "OP_BUILD_MAP",
+	OP_TRY:           "OP_TRY",
+	OP_END_TRY:       "OP_END_TRY",
+	OP_CATCH:         "OP_CATCH",
+	OP_THROW:         "OP_THROW",
+	OP_IMPORT:        "OP_IMPORT",
+	OP_GET_EXPORT:    "OP_GET_EXPORT",
+}
+
+func (op OpCode) String() string {
+	if int(op) < len(opNames) {
+		return opNames[op]
+	}
+	return "OP_UNKNOWN"
+}
+
+// lineRun covers every byte from offset up to the next run's offset.
+type lineRun struct {
+	offset int
+	line   int
+}
+
+// site remembers the token an instruction was compiled from, so runtime
+// errors can be reported the same way the tree-walking interpreter does.
+type site struct {
+	offset int
+	token  tok.Token
+}
+
+type Chunk struct {
+	Code      []byte
+	Constants []any
+	lines     []lineRun
+	sites     []site
+}
+
+func (c *Chunk) Write(b byte, line int) {
+	if len(c.lines) == 0 || c.lines[len(c.lines)-1].line != line {
+		c.lines = append(c.lines, lineRun{offset: len(c.Code), line: line})
+	}
+	c.Code = append(c.Code, b)
+}
+
+// Mark associates token with the instruction that will be written next.
+func (c *Chunk) Mark(token tok.Token) {
+	c.sites = append(c.sites, site{offset: len(c.Code), token: token})
+}
+
+func (c *Chunk) AddConstant(value any) int {
+	c.Constants = append(c.Constants, value)
+	return len(c.Constants) - 1
+}
+
+func (c *Chunk) Line(offset int) int {
+	i := sort.Search(len(c.lines), func(i int) bool { return c.lines[i].offset > offset })
+	if i == 0 {
+		return 0
+	}
+	return c.lines[i-1].line
+}
+
+// Token returns the token recorded for the instruction starting at offset.
+func (c *Chunk) Token(offset int) (tok.Token, bool) {
+	i := sort.Search(len(c.sites), func(i int) bool { return c.sites[i].offset >= offset })
+	if i < len(c.sites) && c.sites[i].offset == offset {
+		return c.sites[i].token, true
+	}
+	return tok.Token{}, false
+}
@@ -0,0 +1,863 @@
+package compiler
+
+import (
+	"errors"
+	"fmt"
+
+	exp "github.com/codecrafters-io/interpreter-starter-go/app/expr"
+	st "github.com/codecrafters-io/interpreter-starter-go/app/stmt"
+	tok "github.com/codecrafters-io/interpreter-starter-go/app/token"
+)
+
+// Local slots and upvalues are 16-bit operands, like constants, so only
+// absurdly large functions fail to compile where the tree-walker would run.
+const maxLocals = 1 << 16
+
+const maxUpvalues = 1 << 16
+
+type local struct {
+	name       string
+	depth      int
+	isCaptured bool
+}
+
+type upvalue struct {
+	index   int
+	isLocal bool
+}
+
+// handler mirrors an exception handler the VM will have installed at this
+// point in the code, so a return can remove it and run finally blocks.
+type handler struct {
+	// finally is nil for the handler that jumps to a catch clause.
+	finally *st.Block
+}
+
+// loop is an enclosing loop that break and continue statements jump out of.
+type loop struct {
+	// depth is the scope depth outside the loop body; locals deeper than
+	// it are popped when jumping.
+	depth int
+	// handlers is how many handlers were installed when the loop began.
+	handlers  int
+	breaks    []int
+	continues []int
+}
+
+// Compiler lowers a resolved AST into bytecode. One Compiler exists per
+// function being compiled; nested functions link back through enclosing.
+type Compiler struct {
+	enclosing  *Compiler
+	function   *Function
+	kind       FunctionType
+	locals     []local
+	upvalues   []upvalue
+	scopeDepth int
+	handlers   []handler
+	loops      []*loop
+	line       int
+	errors     *[]error
+}
+
+func newCompiler(enclosing *Compiler, kind FunctionType, name string) *Compiler {
+	c := &Compiler{
+		enclosing: enclosing,
+		function:  &Function{Name: name},
+		kind:      kind,
+		locals:    make([]local, 0, 8),
+	}
+
+	if enclosing != nil {
+		c.line = enclosing.line
+		c.errors = enclosing.errors
+	} else {
+		c.errors = &[]error{}
+	}
+
+	// Slot zero holds the function being called, or the receiver for methods.
+	slotName := ""
+	if kind == FunctionTypeMethod || kind == FunctionTypeInitializer {
+		slotName = "this"
+	}
+	c.locals = append(c.locals, local{name: slotName, depth: 0})
+
+	return c
+}
+
+// Compile turns a program that already passed the resolver into the
+// top-level script function.
+func Compile(statements []st.Stmt) (*Function, error) {
+	return compile(statements, FunctionTypeScript, false)
+}
+
+// CompileEval is like Compile, but when the last statement is an
+// expression statement the script returns its value.
+func CompileEval(statements []st.Stmt) (*Function, error) {
+	return compile(statements, FunctionTypeScript, true)
+}
+
+// CompileModule compiles the top-level code of an imported file.
+func CompileModule(statements []st.Stmt) (*Function, error) {
+	return compile(statements, FunctionTypeModule, false)
+}
+
+func compile(statements []st.Stmt, kind FunctionType, eval bool) (*Function, error) {
+	c := newCompiler(nil, kind, "")
+
+	for n, statement := range statements {
+		if last, ok := statement.(*st.Expression); ok && eval && n == len(statements)-1 {
+			c.compileExpr(last.Expression)
+			c.emitOp(OP_RETURN)
+			continue
+		}
+		c.compileStmt(statement)
+	}
+
+	function := c.end()
+	if len(*c.errors) > 0 {
+		return nil, errors.Join(*c.errors...)
+	}
+	return function, nil
+}
+
+// Error is a limit of the bytecode format that the program exceeded.
+type Error struct {
+	Token   tok.Token
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("[line %d] Error at '%s': %s", e.Token.Line, string(e.Token.Lexeme), e.Message)
+}
+
+func (c *Compiler) error(token tok.Token, message string) {
+	*c.errors = append(*c.errors, &Error{Token: token, Message: message})
+}
+
+func (c *Compiler) chunk() *Chunk {
+	return &c.function.Chunk
+}
+
+func (c *Compiler) compileStmt(statement st.Stmt) {
+	statement.Accept(c)
+}
+
+func (c *Compiler) compileExpr(expr exp.Expr) {
+	expr.Accept(c)
+}
+
+func (c *Compiler) end() *Function {
+	c.emitReturn()
+	return c.function
+}
+
+// Emitting
+
+func (c *Compiler) emitByte(b byte) {
+	c.chunk().Write(b, c.line)
+}
+
+func (c *Compiler) emitBytes(bytes ...byte) {
+	for _, b := range bytes {
+		c.emitByte(b)
+	}
+}
+
+func (c *Compiler) emitOp(op OpCode) {
+	c.emitByte(byte(op))
+}
+
+// emitOpAt emits an instruction that can fail at runtime, remembering the
+// token to blame.
+func (c *Compiler) emitOpAt(op OpCode, token tok.Token) {
+	c.line = token.Line
+	c.chunk().Mark(token)
+	c.emitByte(byte(op))
+}
+
+func (c *Compiler) emitShort(value int) {
+	c.emitBytes(byte(value>>8), byte(value))
+}
+
+func (c *Compiler) emitReturn() {
+	c.emitReturnValue()
+	c.emitOp(OP_RETURN)
+}
+
+// emitReturnValue pushes what a function returns when it doesn't return a
+// value explicitly.
+func (c *Compiler) emitReturnValue() {
+	if c.kind == FunctionTypeInitializer || c.kind == FunctionTypeModule {
+		c.emitOp(OP_GET_LOCAL)
+		c.emitShort(0)
+	} else {
+		c.emitOp(OP_NIL)
+	}
+}
+
+// makeConstant adds value to the chunk's constants. token is what the
+// value came from, blamed if the chunk is full.
+func (c *Compiler) makeConstant(value any, token tok.Token) int {
+	index := c.chunk().AddConstant(value)
+	if index > 0xffff {
+		c.error(token, "Too many constants in one chunk.")
+		return 0
+	}
+	return index
+}
+
+func (c *Compiler) emitConstant(value any, token tok.Token) {
+	c.emitOp(OP_CONSTANT)
+	c.emitShort(c.makeConstant(value, token))
+}
+
+func (c *Compiler) identifierConstant(name tok.Token) int {
+	return c.makeConstant(string(name.Lexeme), name)
+}
+
+func (c *Compiler) emitJump(op OpCode) int {
+	c.emitOp(op)
+	c.emitBytes(0xff, 0xff)
+	return len(c.chunk().Code) - 2
+}
+
+// patchJump points the jump at offset to the next instruction. token is
+// the statement or operator that jumps, blamed if the jump is too long.
+func (c *Compiler) patchJump(offset int, token tok.Token) {
+	// -2 to adjust for the bytecode for the jump offset itself.
+	jump := len(c.chunk().Code) - offset - 2
+	if jump > 0xffff {
+		c.error(token, "Too much code to jump over.")
+	}
+	c.chunk().Code[offset] = byte(jump >> 8)
+	c.chunk().Code[offset+1] = byte(jump)
+}
+
+// emitTry installs a handler whose code is patched in later, like a jump.
+func (c *Compiler) emitTry(finally *st.Block) int {
+	c.handlers = append(c.handlers, handler{finally: finally})
+	return c.emitJump(OP_TRY)
+}
+
+func (c *Compiler) endTry() {
+	c.emitOp(OP_END_TRY)
+	c.handlers = c.handlers[:len(c.handlers)-1]
+}
+
+// leaveTries removes the handlers a jump out of a try statement leaves,
+// down to the first n, running their finally blocks innermost first.
+func (c *Compiler) leaveTries(n int) {
+	handlers := c.handlers
+	for h := len(handlers) - 1; h >= n; h-- {
+		c.emitOp(OP_END_TRY)
+		c.handlers = handlers[:h]
+		if handlers[h].finally != nil {
+			c.compileStmt(handlers[h].finally)
+		}
+	}
+	c.handlers = handlers
+}
+
+// discardLocals pops the locals deeper than depth without forgetting
+// them, for code that jumps out of their scope.
+func (c *Compiler) discardLocals(depth int) {
+	for n := len(c.locals) - 1; n >= 0 && c.locals[n].depth > depth; n-- {
+		if c.locals[n].isCaptured {
+			c.emitOp(OP_CLOSE_UPVALUE)
+		} else {
+			c.emitOp(OP_POP)
+		}
+	}
+}
+
+func (c *Compiler) emitLoop(loopStart int, token tok.Token) {
+	c.emitOp(OP_LOOP)
+
+	offset := len(c.chunk().Code) - loopStart + 2
+	if offset > 0xffff {
+		c.error(token, "Loop body too large.")
+	}
+	c.emitShort(offset)
+}
+
+// Scopes and variables
+
+func (c *Compiler) beginScope() {
+	c.scopeDepth++
+}
+
+func (c *Compiler) endScope() {
+	c.scopeDepth--
+
+	for len(c.locals) > 0 && c.locals[len(c.locals)-1].depth > c.scopeDepth {
+		if c.locals[len(c.locals)-1].isCaptured {
+			c.emitOp(OP_CLOSE_UPVALUE)
+		} else {
+			c.emitOp(OP_POP)
+		}
+		c.locals = c.locals[:len(c.locals)-1]
+	}
+}
+
+func (c *Compiler) addLocal(name tok.Token) {
+	if len(c.locals) == maxLocals {
+		c.error(name, "Too many local variables in function.")
+		return
+	}
+	c.locals = append(c.locals, local{name: string(name.Lexeme), depth: c.scopeDepth})
+}
+
+func (c *Compiler) resolveLocal(name string) int {
+	for i := len(c.locals) - 1; i >= 0; i-- {
+		if c.locals[i].name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (c *Compiler) addUpvalue(token tok.Token, index int, isLocal bool) int {
+	for i, uv := range c.upvalues {
+		if uv.index == index && uv.isLocal == isLocal {
+			return i
+		}
+	}
+
+	if len(c.upvalues) == maxUpvalues {
+		c.error(token, "Too many closure variables in function.")
+		return 0
+	}
+
+	c.upvalues = append(c.upvalues, upvalue{index: index, isLocal: isLocal})
+	c.function.UpvalueCount = len(c.upvalues)
+	return len(c.upvalues) - 1
+}
+
+func (c *Compiler) resolveUpvalue(token tok.Token, name string) int {
+	if c.enclosing == nil {
+		return -1
+	}
+
+	if local := c.enclosing.resolveLocal(name); local != -1 {
+		c.enclosing.locals[local].isCaptured = true
+		return c.addUpvalue(token, local, true)
+	}
+
+	if upvalue := c.enclosing.resolveUpvalue(token, name); upvalue != -1 {
+		return c.addUpvalue(token, upvalue, false)
+	}
+
+	return -1
+}
+
+// declareVariable binds name in the current scope once its initializer
+// has been compiled. Top-level names are globals and need no slot.
+func (c *Compiler) declareVariable(name tok.Token) {
+	if c.scopeDepth == 0 {
+		return
+	}
+	c.addLocal(name)
+}
+
+func (c *Compiler) defineVariable(name tok.Token) {
+	if c.scopeDepth > 0 {
+		return
+	}
+	c.emitOpAt(OP_DEFINE_GLOBAL, name)
+	c.emitShort(c.identifierConstant(name))
+}
+
+func (c *Compiler) namedVariable(name tok.Token, assign exp.Expr) {
+	var getOp, setOp OpCode
+	var arg int
+
+	lexeme := string(name.Lexeme)
+	if arg = c.resolveLocal(lexeme); arg != -1 {
+		getOp, setOp = OP_GET_LOCAL, OP_SET_LOCAL
+	} else if arg = c.resolveUpvalue(name, lexeme); arg != -1 {
+		getOp, setOp = OP_GET_UPVALUE, OP_SET_UPVALUE
+	} else {
+		arg = c.identifierConstant(name)
+		getOp, setOp = OP_GET_GLOBAL, OP_SET_GLOBAL
+	}
+
+	op := getOp
+	if assign != nil {
+		c.compileExpr(assign)
+		op = setOp
+	}
+
+	c.emitOpAt(op, name)
+	c.emitShort(arg)
+}
+
+func (c *Compiler) compileFunction(declaration *st.Function, kind FunctionType) {
+	fc := newCompiler(c, kind, string(declaration.Name.Lexeme))
+	fc.line = declaration.Name.Line
+	fc.beginScope()
+
+	for _, param := range declaration.Params {
+		fc.function.Arity++
+		fc.addLocal(param)
+	}
+
+	for _, statement := range declaration.Body {
+		fc.compileStmt(statement)
+	}
+
+	function := fc.end()
+
+	c.emitOpAt(OP_CLOSURE, declaration.Name)
+	c.emitShort(c.makeConstant(function, declaration.Name))
+	for _, uv := range fc.upvalues {
+		isLocal := byte(0)
+		if uv.isLocal {
+			isLocal = 1
+		}
+		c.emitByte(isLocal)
+		c.emitShort(uv.index)
+	}
+}
+
+// Expression visitor
+
+func (c *Compiler) VisitLiteralExpr(expr *exp.Literal) any {
+	switch v := expr.Value.(type) {
+	case nil:
+		c.emitOp(OP_NIL)
+	case bool:
+		if v {
+			c.emitOp(OP_TRUE)
+		} else {
+			c.emitOp(OP_FALSE)
+		}
+	case []rune:
+		c.emitConstant(string(v), expr.Token)
+	default:
+		c.emitConstant(v, expr.Token)
+	}
+	return nil
+}
+
+func (c *Compiler) VisitGroupingExpr(expr *exp.Grouping) any {
+	c.compileExpr(expr.Expression)
+	return nil
+}
+
+func (c *Compiler) VisitUnaryExpr(expr *exp.Unary) any {
+	c.compileExpr(expr.Right)
+
+	switch expr.Operator.Type {
+	case tok.MINUS:
+		c.emitOpAt(OP_NEGATE, expr.Operator)
+	case tok.BANG:
+		c.emitOpAt(OP_NOT, expr.Operator)
+	}
+	return nil
+}
+
+func (c *Compiler) VisitBinaryExpr(expr *exp.Binary) any {
+	c.compileExpr(expr.Left)
+	c.compileExpr(expr.Right)
+
+	switch expr.Operator.Type {
+	case tok.BANG_EQUAL:
+		c.emitOpAt(OP_EQUAL, expr.Operator)
+		c.emitOp(OP_NOT)
+	case tok.EQUAL_EQUAL:
+		c.emitOpAt(OP_EQUAL, expr.Operator)
+	case tok.GREATER:
+		c.emitOpAt(OP_GREATER, expr.Operator)
+	case tok.GREATER_EQUAL:
+		c.emitOpAt(OP_GREATER_EQUAL, expr.Operator)
+	case tok.LESS:
+		c.emitOpAt(OP_LESS, expr.Operator)
+	case tok.LESS_EQUAL:
+		c.emitOpAt(OP_LESS_EQUAL, expr.Operator)
+	case tok.PLUS:
+		c.emitOpAt(OP_ADD, expr.Operator)
+	case tok.MINUS:
+		c.emitOpAt(OP_SUBTRACT, expr.Operator)
+	case tok.STAR:
+		c.emitOpAt(OP_MULTIPLY, expr.Operator)
+	case tok.SLASH:
+		c.emitOpAt(OP_DIVIDE, expr.Operator)
+	}
+	return nil
+}
+
+func (c *Compiler) VisitLogicalExpr(expr *exp.Logical) any {
+	c.compileExpr(expr.Left)
+	c.line = expr.Operator.Line
+
+	if expr.Operator.Type == tok.AND {
+		endJump := c.emitJump(OP_JUMP_IF_FALSE)
+		c.emitOp(OP_POP)
+		c.compileExpr(expr.Right)
+		c.patchJump(endJump, expr.Operator)
+		return nil
+	}
+
+	elseJump := c.emitJump(OP_JUMP_IF_FALSE)
+	endJump := c.emitJump(OP_JUMP)
+	c.patchJump(elseJump, expr.Operator)
+	c.emitOp(OP_POP)
+	c.compileExpr(expr.Right)
+	c.patchJump(endJump, expr.Operator)
+	return nil
+}
+
+func (c *Compiler) VisitVariableExpr(expr *exp.Variable) any {
+	c.namedVariable(expr.Name, nil)
+	return nil
+}
+
+func (c *Compiler) VisitAssignExpr(expr *exp.Assign) any {
+	c.namedVariable(expr.Name, expr.Value)
+	return nil
+}
+
+func (c *Compiler) VisitCallExpr(expr *exp.Call) any {
+	c.compileExpr(expr.Callee)
+	for _, arg := range expr.Arguments {
+		c.compileExpr(arg)
+	}
+	c.emitOpAt(OP_CALL, expr.Paren)
+	c.emitByte(byte(len(expr.Arguments)))
+	return nil
+}
+
+func (c *Compiler) VisitGetExpr(expr *exp.Get) any {
+	c.compileExpr(expr.Object)
+	c.emitOpAt(OP_GET_PROPERTY, expr.Name)
+	c.emitShort(c.identifierConstant(expr.Name))
+	return nil
+}
+
+func (c *Compiler) VisitSetExpr(expr *exp.Set) any {
+	c.compileExpr(expr.Object)
+	c.compileExpr(expr.Value)
+	c.emitOpAt(OP_SET_PROPERTY, expr.Name)
+	c.emitShort(c.identifierConstant(expr.Name))
+	return nil
+}
+
+func (c *Compiler) VisitListExpr(expr *exp.List) any {
+	for _, element := range expr.Elements {
+		c.compileExpr(element)
+	}
+	if len(expr.Elements) > 0xffff {
+		c.error(expr.Bracket, "Too many elements in list literal.")
+	}
+	c.emitOpAt(OP_BUILD_LIST, expr.Bracket)
+	c.emitShort(len(expr.Elements))
+	return nil
+}
+
+func (c *Compiler) VisitIndexExpr(expr *exp.Index) any {
+	c.compileExpr(expr.Object)
+	c.compileExpr(expr.Index)
+	c.emitOpAt(OP_GET_INDEX, expr.Bracket)
+	return nil
+}
+
+func (c *Compiler) VisitIndexSetExpr(expr *exp.IndexSet) any {
+	c.compileExpr(expr.Object)
+	c.compileExpr(expr.Index)
+	c.compileExpr(expr.Value)
+	c.emitOpAt(OP_SET_INDEX, expr.Bracket)
+	return nil
+}
+
+func (c *Compiler) VisitMapExpr(expr *exp.Map) any {
+	for n, key := range expr.Keys {
+		c.compileExpr(key)
+		c.compileExpr(expr.Values[n])
+	}
+	if len(expr.Keys) > 0xffff {
+		c.error(expr.Brace, "Too many entries in map literal.")
+	}
+	c.emitOpAt(OP_BUILD_MAP, expr.Brace)
+	c.emitShort(len(expr.Keys))
+	return nil
+}
+
+func (c *Compiler) VisitThisExpr(expr *exp.This) any {
+	c.namedVariable(expr.Keyword, nil)
+	return nil
+}
+
+func (c *Compiler) VisitSuperExpr(expr *exp.Super) any {
+	c.namedVariable(thisToken(expr.Keyword), nil)
+	c.namedVariable(expr.Keyword, nil)
+	c.emitOpAt(OP_GET_SUPER, expr.Method)
+	c.emitShort(c.identifierConstant(expr.Method))
+	return nil
+}
+
+func (c *Compiler) VisitFunctionExpr(expr *exp.Function) any {
+	c.compileFunction(expr.Declaration.(*st.Function), FunctionTypeFunction)
+	return nil
+}
+
+// Statement visitor
+
+func (c *Compiler) VisitExpressionStmt(stmt *st.Expression) any {
+	c.compileExpr(stmt.Expression)
+	c.emitOp(OP_POP)
+	return nil
+}
+
+func (c *Compiler) VisitPrintStmt(stmt *st.Print) any {
+	c.compileExpr(stmt.Expression)
+	c.emitOp(OP_PRINT)
+	return nil
+}
+
+func (c *Compiler) VisitVarStmt(stmt *st.Var) any {
+	c.line = stmt.Name.Line
+	if stmt.Initializer != nil {
+		c.compileExpr(stmt.Initializer)
+	} else {
+		c.emitOp(OP_NIL)
+	}
+
+	// Declared after the initializer so "var a = a;" reads the outer a,
+	// matching the tree-walking interpreter.
+	c.declareVariable(stmt.Name)
+	c.defineVariable(stmt.Name)
+	return nil
+}
+
+func (c *Compiler) VisitBlockStmt(stmt *st.Block) any {
+	c.beginScope()
+	for _, statement := range stmt.Statements {
+		c.compileStmt(statement)
+	}
+	c.endScope()
+	return nil
+}
+
+func (c *Compiler) VisitIfStmt(stmt *st.If) any {
+	c.compileExpr(stmt.Condition)
+
+	thenJump := c.emitJump(OP_JUMP_IF_FALSE)
+	c.emitOp(OP_POP)
+	c.compileStmt(stmt.ThenBranch)
+
+	elseJump := c.emitJump(OP_JUMP)
+	c.patchJump(thenJump, stmt.Keyword)
+	c.emitOp(OP_POP)
+
+	if stmt.ElseBranch != nil {
+		c.compileStmt(stmt.ElseBranch)
+	}
+	c.patchJump(elseJump, stmt.Keyword)
+	return nil
+}
+
+func (c *Compiler) VisitWhileStmt(stmt *st.While) any {
+	loopStart := len(c.chunk().Code)
+	c.compileExpr(stmt.Condition)
+
+	exitJump := c.emitJump(OP_JUMP_IF_FALSE)
+	c.emitOp(OP_POP)
+
+	l := &loop{depth: c.scopeDepth, handlers: len(c.handlers)}
+	c.loops = append(c.loops, l)
+	c.compileStmt(stmt.Body)
+	c.loops = c.loops[:len(c.loops)-1]
+
+	// continue jumps forward to the increment, which is then followed by
+	// the jump back to the condition.
+	for _, jump := range l.continues {
+		c.patchJump(jump, stmt.Keyword)
+	}
+	if stmt.Increment != nil {
+		c.compileExpr(stmt.Increment)
+		c.emitOp(OP_POP)
+	}
+	c.emitLoop(loopStart, stmt.Keyword)
+
+	c.patchJump(exitJump, stmt.Keyword)
+	c.emitOp(OP_POP)
+	for _, jump := range l.breaks {
+		c.patchJump(jump, stmt.Keyword)
+	}
+	return nil
+}
+
+func (c *Compiler) VisitFunctionStmt(stmt *st.Function) any {
+	// Declared before the body so the function can refer to itself.
+	c.declareVariable(stmt.Name)
+	c.compileFunction(stmt, FunctionTypeFunction)
+	c.defineVariable(stmt.Name)
+	return nil
+}
+
+func (c *Compiler) VisitReturnStmt(stmt *st.Return) any {
+	c.line = stmt.Keyword.Line
+	if stmt.Value == nil {
+		c.emitReturnValue()
+	} else {
+		c.compileExpr(stmt.Value)
+	}
+
+	// The return value gets a slot of its own while finally blocks run,
+	// and is pushed again afterwards.
+	if len(c.handlers) > 0 {
+		c.addLocal(stmt.Keyword)
+		slot := len(c.locals) - 1
+		c.locals[slot].name = ""
+		c.leaveTries(0)
+		c.emitOp(OP_GET_LOCAL)
+		c.emitShort(slot)
+		c.locals = c.locals[:slot]
+	}
+	c.emitOp(OP_RETURN)
+	return nil
+}
+
+func (c *Compiler) VisitBreakStmt(stmt *st.Break) any {
+	c.line = stmt.Keyword.Line
+	l := c.loops[len(c.loops)-1]
+	c.leaveTries(l.handlers)
+	c.discardLocals(l.depth)
+	l.breaks = append(l.breaks, c.emitJump(OP_JUMP))
+	return nil
+}
+
+func (c *Compiler) VisitContinueStmt(stmt *st.Continue) any {
+	c.line = stmt.Keyword.Line
+	l := c.loops[len(c.loops)-1]
+	c.leaveTries(l.handlers)
+	c.discardLocals(l.depth)
+	l.continues = append(l.continues, c.emitJump(OP_JUMP))
+	return nil
+}
+
+// VisitImportStmt leaves the module on the stack while the names imported
+// from it are defined. Imports only happen at the top level, so those are
+// always globals.
+func (c *Compiler) VisitImportStmt(stmt *st.Import) any {
+	c.emitOpAt(OP_IMPORT, stmt.Path)
+	c.emitShort(c.makeConstant(string(stmt.Path.Literal.([]rune)), stmt.Path))
+	if len(stmt.Names) == 0 {
+		c.defineVariable(stmt.Alias)
+		return nil
+	}
+
+	for _, name := range stmt.Names {
+		c.emitOpAt(OP_GET_EXPORT, name)
+		c.emitShort(c.identifierConstant(name))
+		c.defineVariable(name)
+	}
+	c.emitOp(OP_POP)
+	return nil
+}
+
+func (c *Compiler) VisitThrowStmt(stmt *st.Throw) any {
+	c.compileExpr(stmt.Value)
+	c.emitOpAt(OP_THROW, stmt.Keyword)
+	return nil
+}
+
+// VisitTryStmt installs a handler for the catch clause inside one for the
+// finally block. The VM jumps to a handler with the error on the stack.
+func (c *Compiler) VisitTryStmt(stmt *st.Try) any {
+	c.line = stmt.Keyword.Line
+	finallyHandler := -1
+	if stmt.Finally != nil {
+		finallyHandler = c.emitTry(stmt.Finally)
+	}
+
+	if stmt.Catch == nil {
+		c.compileStmt(stmt.Body)
+	} else {
+		catchHandler := c.emitTry(nil)
+		c.compileStmt(stmt.Body)
+		c.endTry()
+		skip := c.emitJump(OP_JUMP)
+
+		c.patchJump(catchHandler, stmt.Keyword)
+		c.beginScope()
+		c.emitOp(OP_CATCH)
+		c.addLocal(stmt.CatchName)
+		for _, statement := range stmt.Catch.Statements {
+			c.compileStmt(statement)
+		}
+		c.endScope()
+		c.patchJump(skip, stmt.Keyword)
+	}
+
+	if stmt.Finally == nil {
+		return nil
+	}
+	c.endTry()
+	c.compileStmt(stmt.Finally)
+	end := c.emitJump(OP_JUMP)
+
+	// An error raised in the body or the catch clause runs the finally
+	// block and is then raised again. OP_THROW passes it on unchanged.
+	c.patchJump(finallyHandler, stmt.Keyword)
+	c.addLocal(stmt.Keyword)
+	slot := len(c.locals) - 1
+	c.locals[slot].name = ""
+	c.compileStmt(stmt.Finally)
+	c.emitOp(OP_GET_LOCAL)
+	c.emitShort(slot)
+	c.emitOpAt(OP_THROW, stmt.Keyword)
+	c.locals = c.locals[:slot]
+
+	c.patchJump(end, stmt.Keyword)
+	return nil
+}
+
+func (c *Compiler) VisitClassStmt(stmt *st.Class) any {
+	nameConstant := c.identifierConstant(stmt.Name)
+	c.declareVariable(stmt.Name)
+
+	c.emitOpAt(OP_CLASS, stmt.Name)
+	c.emitShort(nameConstant)
+	c.defineVariable(stmt.Name)
+
+	// The superclass lives in a scope of its own, so methods can capture it
+	// as "super".
+	if stmt.Superclass != nil {
+		c.VisitVariableExpr(stmt.Superclass)
+
+		c.beginScope()
+		c.addLocal(superToken(stmt.Superclass.Name))
+
+		c.namedVariable(stmt.Name, nil)
+		c.emitOpAt(OP_INHERIT, stmt.Superclass.Name)
+	}
+
+	c.namedVariable(stmt.Name, nil)
+	for _, method := range stmt.Methods {
+		kind := FunctionTypeMethod
+		if string(method.Name.Lexeme) == "init" {
+			kind = FunctionTypeInitializer
+		}
+		c.compileFunction(method, kind)
+		c.emitOpAt(OP_METHOD, method.Name)
+		c.emitShort(c.identifierConstant(method.Name))
+	}
+	c.emitOp(OP_POP)
+
+	if stmt.Superclass != nil {
+		c.endScope()
+	}
+	return nil
+}
+
+func thisToken(at tok.Token) tok.Token {
+	return tok.NewToken(tok.THIS, []rune("this"), nil, at.Line)
+}
+
+func superToken(at tok.Token) tok.Token {
+	return tok.NewToken(tok.SUPER, []rune("super"), nil, at.Line)
+}
+
+var _ exp.ExprVisitor = (*Compiler)(nil)
+var _ st.StmtVisitor = (*Compiler)(nil)
@@ -0,0 +1,29 @@
+package compiler
+
+import "fmt"
+
+type FunctionType int
+
+const (
+	FunctionTypeScript FunctionType = iota
+	FunctionTypeFunction
+	FunctionTypeMethod
+	FunctionTypeInitializer
+	// FunctionTypeModule is the top-level code of an imported file. It
+	// returns its module, which the VM keeps in slot zero.
+	FunctionTypeModule
+)
+
+type Function struct {
+	Name         string
+	Arity        int
+	UpvalueCount int
+	Chunk        Chunk
+}
+
+func (f *Function) String() string {
+	if f.Name == "" {
+		return "<script>"
+	}
+	return fmt.Sprintf("<fn %s>", f.Name)
+}
@@ -0,0 +1,315 @@
+// Package coverage records which lines and branches of a Lox program ran.
+// Instrument registers what could run, then the interpreter reports what
+// did. The result can be printed as annotated source or written as LCOV.
+package coverage
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	exp "github.com/codecrafters-io/interpreter-starter-go/app/expr"
+	st "github.com/codecrafters-io/interpreter-starter-go/app/stmt"
+	tok "github.com/codecrafters-io/interpreter-starter-go/app/token"
+)
+
+// Branch is a point where execution goes one of two ways: an if, a loop
+// condition, or the right operand of and/or.
+type Branch struct {
+	Line int
+	// Kind is "if", "while", "for", "and" or "or".
+	Kind string
+	// Taken counts each way. Arm 0 is the then branch, the loop body, or
+	// evaluating the right operand; arm 1 is the else branch, leaving the
+	// loop, or short-circuiting.
+	Taken [2]int
+}
+
+func (b *Branch) arms() [2]string {
+	switch b.Kind {
+	case "if":
+		return [2]string{"then", "else"}
+	case "while", "for":
+		return [2]string{"body", "exit"}
+	default:
+		return [2]string{"right", "short-circuit"}
+	}
+}
+
+// Coverage is not safe for concurrent use.
+type Coverage struct {
+	// Filename is the script being measured, recorded in LCOV output.
+	Filename string
+
+	// lines maps each line with a statement to how often one ran.
+	lines map[int]int
+	// statements are the ones Instrument registered.
+	statements map[st.Stmt]bool
+	branches   map[any]*Branch
+	order      []*Branch
+}
+
+func New(filename string) *Coverage {
+	return &Coverage{
+		Filename:   filename,
+		lines:      make(map[int]int),
+		statements: make(map[st.Stmt]bool),
+		branches:   make(map[any]*Branch),
+	}
+}
+
+// Instrument registers the lines and branches in statements, so the ones
+// that never run are reported too.
+func (c *Coverage) Instrument(statements []st.Stmt) {
+	for _, stmt := range statements {
+		c.stmt(stmt)
+	}
+}
+
+func (c *Coverage) stmt(stmt st.Stmt) {
+	if _, ok := stmt.(*st.Block); !ok {
+		c.statements[stmt] = true
+		if _, ok := c.lines[st.Span(stmt).Line]; !ok {
+			c.lines[st.Span(stmt).Line] = 0
+		}
+	}
+
+	switch stmt := stmt.(type) {
+	case *st.Expression:
+		c.expr(stmt.Expression)
+	case *st.Print:
+		c.expr(stmt.Expression)
+	case *st.Var:
+		if stmt.Initializer != nil {
+			c.expr(stmt.Initializer)
+		}
+	case *st.Block:
+		c.Instrument(stmt.Statements)
+	case *st.If:
+		c.branch(stmt, stmt.Keyword.Line, "if")
+		c.expr(stmt.Condition)
+		c.stmt(stmt.ThenBranch)
+		if stmt.ElseBranch != nil {
+			c.stmt(stmt.ElseBranch)
+		}
+	case *st.While:
+		kind := "while"
+		if stmt.Keyword.Type == tok.FOR {
+			kind = "for"
+		}
+		c.branch(stmt, stmt.Keyword.Line, kind)
+		c.expr(stmt.Condition)
+		c.stmt(stmt.Body)
+		if stmt.Increment != nil {
+			c.expr(stmt.Increment)
+		}
+	case *st.Function:
+		c.Instrument(stmt.Body)
+	case *st.Class:
+		// Methods are declared by the class statement, so only their
+		// bodies have lines of their own.
+		for _, method := range stmt.Methods {
+			c.Instrument(method.Body)
+		}
+	case *st.Return:
+		if stmt.Value != nil {
+			c.expr(stmt.Value)
+		}
+	case *st.Throw:
+		c.expr(stmt.Value)
+	case *st.Try:
+		c.stmt(stmt.Body)
+		if stmt.Catch != nil {
+			c.stmt(stmt.Catch)
+		}
+		if stmt.Finally != nil {
+			c.stmt(stmt.Finally)
+		}
+	}
+}
+
+func (c *Coverage) expr(expr exp.Expr) {
+	switch expr := expr.(type) {
+	case *exp.Logical:
+		c.branch(expr, expr.Operator.Line, string(expr.Operator.Lexeme))
+		c.expr(expr.Left)
+		c.expr(expr.Right)
+	case *exp.Binary:
+		c.expr(expr.Left)
+		c.expr(expr.Right)
+	case *exp.Unary:
+		c.expr(expr.Right)
+	case *exp.Grouping:
+		c.expr(expr.Expression)
+	case *exp.Assign:
+		c.expr(expr.Value)
+	case *exp.Call:
+		c.expr(expr.Callee)
+		for _, argument := range expr.Arguments {
+			c.expr(argument)
+		}
+	case *exp.Get:
+		c.expr(expr.Object)
+	case *exp.Set:
+		c.expr(expr.Object)
+		c.expr(expr.Value)
+	case *exp.List:
+		for _, element := range expr.Elements {
+			c.expr(element)
+		}
+	case *exp.Map:
+		for n := range expr.Keys {
+			c.expr(expr.Keys[n])
+			c.expr(expr.Values[n])
+		}
+	case *exp.Index:
+		c.expr(expr.Object)
+		c.expr(expr.Index)
+	case *exp.IndexSet:
+		c.expr(expr.Object)
+		c.expr(expr.Index)
+		c.expr(expr.Value)
+	case *exp.Function:
+		c.Instrument(expr.Declaration.(*st.Function).Body)
+	}
+}
+
+func (c *Coverage) branch(node any, line int, kind string) {
+	if _, ok := c.branches[node]; ok {
+		return
+	}
+	b := &Branch{Line: line, Kind: kind}
+	c.branches[node] = b
+	c.order = append(c.order, b)
+}
+
+// Run records that a statement ran. Statements that Instrument never
+// registered, such as those of imported modules, don't count.
+func (c *Coverage) Run(stmt st.Stmt) {
+	if c.statements[stmt] {
+		c.lines[st.Span(stmt).Line]++
+	}
+}
+
+// Take records the branch at node, an *st.If, *st.While or *exp.Logical,
+// going to arm 0 or 1.
+func (c *Coverage) Take(node any, arm int) {
+	if b, ok := c.branches[node]; ok {
+		b.Taken[arm]++
+	}
+}
+
+// Lines returns the lines that have statements, in order.
+func (c *Coverage) Lines() []int {
+	lines := make([]int, 0, len(c.lines))
+	for line := range c.lines {
+		lines = append(lines, line)
+	}
+	sort.Ints(lines)
+	return lines
+}
+
+// Hits is how many statements on line ran.
+func (c *Coverage) Hits(line int) int {
+	return c.lines[line]
+}
+
+// Branches returns the branch points in source order.
+func (c *Coverage) Branches() []*Branch {
+	branches := append([]*Branch(nil), c.order...)
+	sort.SliceStable(branches, func(a, b int) bool { return branches[a].Line < branches[b].Line })
+	return branches
+}
+
+// Summary counts the lines that ran and the branch arms that were taken.
+func (c *Coverage) Summary() (lines, linesHit, arms, armsTaken int) {
+	for _, hits := range c.lines {
+		lines++
+		if hits > 0 {
+			linesHit++
+		}
+	}
+	for _, b := range c.order {
+		for _, taken := range b.Taken {
+			arms++
+			if taken > 0 {
+				armsTaken++
+			}
+		}
+	}
+	return
+}
+
+func percent(part, whole int) float64 {
+	if whole == 0 {
+		return 100
+	}
+	return 100 * float64(part) / float64(whole)
+}
+
+// WriteReport prints source with each line's hit count in the margin:
+// "-" for lines without statements and "#####" for lines that never ran.
+// Branch points follow their line, with a "!" when an arm was not taken.
+func (c *Coverage) WriteReport(w io.Writer, source string) {
+	lines, linesHit, arms, armsTaken := c.Summary()
+	fmt.Fprintf(w, "Coverage of %s: %d/%d lines (%.1f%%), %d/%d branches (%.1f%%)\n\n",
+		c.Filename, linesHit, lines, percent(linesHit, lines), armsTaken, arms, percent(armsTaken, arms))
+
+	branches := make(map[int][]*Branch)
+	for _, b := range c.Branches() {
+		branches[b.Line] = append(branches[b.Line], b)
+	}
+	for n, text := range strings.Split(strings.TrimSuffix(source, "\n"), "\n") {
+		line := n + 1
+		count := "-"
+		if hits, ok := c.lines[line]; ok {
+			count = fmt.Sprint(hits)
+			if hits == 0 {
+				count = "#####"
+			}
+		}
+		fmt.Fprintf(w, "%6s | %4d | %s\n", count, line, text)
+		for _, b := range branches[line] {
+			marker := " "
+			if b.Taken[0] == 0 || b.Taken[1] == 0 {
+				marker = "!"
+			}
+			arms := b.arms()
+			fmt.Fprintf(w, "%6s |      |   %s: %s %d, %s %d\n", marker, b.Kind, arms[0], b.Taken[0], arms[1], b.Taken[1])
+		}
+	}
+}
+
+// WriteLCOV writes a tracefile in the format of the Linux Test Project's
+// lcov tool, which genhtml and most coverage services read.
+func (c *Coverage) WriteLCOV(w io.Writer) error {
+	var out strings.Builder
+	out.WriteString("TN:\n")
+	fmt.Fprintf(&out, "SF:%s\n", c.Filename)
+
+	// LCOV numbers the branch points on each line from 0.
+	blocks := make(map[int]int)
+	for _, b := range c.Branches() {
+		block := blocks[b.Line]
+		blocks[b.Line]++
+		for arm, taken := range b.Taken {
+			count := "-"
+			if b.Taken[0]+b.Taken[1] > 0 {
+				count = fmt.Sprint(taken)
+			}
+			fmt.Fprintf(&out, "BRDA:%d,%d,%d,%s\n", b.Line, block, arm, count)
+		}
+	}
+	lines, linesHit, arms, armsTaken := c.Summary()
+	fmt.Fprintf(&out, "BRF:%d\nBRH:%d\n", arms, armsTaken)
+
+	for _, line := range c.Lines() {
+		fmt.Fprintf(&out, "DA:%d,%d\n", line, c.lines[line])
+	}
+	fmt.Fprintf(&out, "LF:%d\nLH:%d\n", lines, linesHit)
+	out.WriteString("end_of_record\n")
+
+	_, e := io.WriteString(w, out.String())
+	return e
+}
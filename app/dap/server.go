@@ -0,0 +1,466 @@
+// Package dap implements the Debug Adapter Protocol for Lox, so editors
+// such as VS Code can debug programs through debug.Debugger. Messages are
+// read from and written to a pair of streams, normally stdin and stdout.
+package dap
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/codecrafters-io/interpreter-starter-go/app/coverage"
+	"github.com/codecrafters-io/interpreter-starter-go/app/debug"
+	env "github.com/codecrafters-io/interpreter-starter-go/app/environment"
+	"github.com/codecrafters-io/interpreter-starter-go/app/lox"
+)
+
+// threadID is the only thread a Lox program has.
+const threadID = 1
+
+type request struct {
+	Seq       int             `json:"seq"`
+	Command   string          `json:"command"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// command is sent to the program while it is stopped. Inspecting state
+// happens on the program's own goroutine, since the interpreter is not
+// safe for concurrent use.
+type command struct {
+	// inspect runs against the stop and its result is sent on done.
+	inspect func(stop debug.Stop) (any, error)
+	done    chan result
+	// Otherwise the program resumes in mode, or is abandoned if quit is
+	// set.
+	mode debug.Mode
+	quit bool
+}
+
+type result struct {
+	body any
+	err  error
+}
+
+type Server struct {
+	in *bufio.Reader
+
+	// mu serialises writes to out, which come from both the request loop
+	// and the running program.
+	mu  sync.Mutex
+	out io.Writer
+	seq int
+
+	debugger *debug.Debugger
+	source   string
+	machine  *lox.VM
+	cancel   context.CancelFunc
+	commands chan command
+
+	// resumed is sent to the program once the request that resumes it
+	// has been answered, so the response comes before the next stop.
+	resumed *command
+
+	// stopped is set while the program waits in the stop handler.
+	stopped bool
+	// handles maps variablesReference values to the environments they
+	// were handed out for. They are only valid until the program resumes.
+	handles []*env.Environment
+}
+
+func NewServer(in io.Reader, out io.Writer) *Server {
+	s := &Server{
+		in:       bufio.NewReader(in),
+		out:      out,
+		commands: make(chan command),
+	}
+	s.debugger = debug.New(s.stop)
+	return s
+}
+
+// Run serves requests until the client disconnects or closes the input.
+func (s *Server) Run() error {
+	for {
+		body, e := s.read()
+		if e == io.EOF {
+			s.abandon()
+			return nil
+		}
+		if e != nil {
+			return e
+		}
+
+		var r request
+		if e := json.Unmarshal(body, &r); e != nil {
+			return fmt.Errorf("bad message: %w", e)
+		}
+		reply, failure := s.handle(r)
+		if e := s.respond(r, reply, failure); e != nil {
+			return e
+		}
+		if s.resumed != nil {
+			s.commands <- *s.resumed
+			s.resumed = nil
+		}
+		switch r.Command {
+		case "initialize":
+			s.event("initialized", nil)
+		case "disconnect", "terminate":
+			return nil
+		}
+	}
+}
+
+func (s *Server) read() ([]byte, error) {
+	header, e := textproto.NewReader(s.in).ReadMIMEHeader()
+	if e != nil {
+		return nil, e
+	}
+	length, e := strconv.Atoi(header.Get("Content-Length"))
+	if e != nil {
+		return nil, fmt.Errorf("bad Content-Length: %w", e)
+	}
+	body := make([]byte, length)
+	_, e = io.ReadFull(s.in, body)
+	return body, e
+}
+
+func (s *Server) write(message map[string]any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	message["seq"] = s.seq
+	body, e := json.Marshal(message)
+	if e != nil {
+		return e
+	}
+	_, e = fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return e
+}
+
+func (s *Server) respond(r request, body any, failure error) error {
+	message := map[string]any{
+		"type":        "response",
+		"request_seq": r.Seq,
+		"command":     r.Command,
+		"success":     failure == nil,
+	}
+	if failure != nil {
+		message["message"] = failure.Error()
+	} else if body != nil {
+		message["body"] = body
+	}
+	return s.write(message)
+}
+
+func (s *Server) event(name string, body any) {
+	message := map[string]any{"type": "event", "event": name}
+	if body != nil {
+		message["body"] = body
+	}
+	s.write(message)
+}
+
+// output sends print statements and errors to the client as output
+// events.
+type output struct {
+	server   *Server
+	category string
+}
+
+func (o output) Write(p []byte) (int, error) {
+	o.server.event("output", map[string]any{"category": o.category, "output": string(p)})
+	return len(p), nil
+}
+
+func (s *Server) handle(r request) (any, error) {
+	switch r.Command {
+	case "initialize":
+		return map[string]any{
+			"supportsConfigurationDoneRequest": true,
+			"supportsEvaluateForHovers":        true,
+			"supportsTerminateRequest":         true,
+		}, nil
+
+	case "launch":
+		var args struct {
+			Program     string `json:"program"`
+			StopOnEntry bool   `json:"stopOnEntry"`
+		}
+		if e := json.Unmarshal(r.Arguments, &args); e != nil {
+			return nil, e
+		}
+		source, e := os.ReadFile(args.Program)
+		if e != nil {
+			return nil, e
+		}
+		s.source = string(source)
+		s.debugger.SetStopOnEntry(args.StopOnEntry)
+		s.machine = lox.NewVM(lox.Options{
+			Stdout: output{s, "stdout"},
+			Stderr: output{s, "stderr"},
+			Hook:   s.debugger.Hook,
+			File:   args.Program,
+		})
+		return nil, nil
+
+	case "setBreakpoints":
+		var args struct {
+			Source struct {
+				Path string `json:"path"`
+			} `json:"source"`
+			Breakpoints []struct {
+				Line int `json:"line"`
+			} `json:"breakpoints"`
+		}
+		if e := json.Unmarshal(r.Arguments, &args); e != nil {
+			return nil, e
+		}
+		// Each request replaces all the breakpoints in one file.
+		file, e := filepath.Abs(args.Source.Path)
+		if e != nil {
+			return nil, e
+		}
+		s.debugger.ClearFileBreakpoints(file)
+		lines := statementLines(file)
+		verified := make([]map[string]any, 0, len(args.Breakpoints))
+		for _, breakpoint := range args.Breakpoints {
+			s.debugger.SetBreakpoint(file, breakpoint.Line)
+			if lines[breakpoint.Line] {
+				verified = append(verified, map[string]any{"verified": true, "line": breakpoint.Line})
+			} else {
+				verified = append(verified, map[string]any{"verified": false, "line": breakpoint.Line, "message": "No statement on this line."})
+			}
+		}
+		return map[string]any{"breakpoints": verified}, nil
+
+	case "configurationDone":
+		if s.machine == nil {
+			return nil, errors.New("Launch a program first.")
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		s.cancel = cancel
+		go s.run(ctx)
+		return nil, nil
+
+	case "threads":
+		return map[string]any{"threads": []map[string]any{{"id": threadID, "name": "main"}}}, nil
+
+	case "stackTrace":
+		return s.inspect(s.stackTrace)
+	case "scopes":
+		var args struct {
+			FrameID int `json:"frameId"`
+		}
+		if e := json.Unmarshal(r.Arguments, &args); e != nil {
+			return nil, e
+		}
+		return s.inspect(func(stop debug.Stop) (any, error) { return s.scopes(stop, args.FrameID) })
+	case "variables":
+		var args struct {
+			VariablesReference int `json:"variablesReference"`
+		}
+		if e := json.Unmarshal(r.Arguments, &args); e != nil {
+			return nil, e
+		}
+		return s.inspect(func(stop debug.Stop) (any, error) { return s.variables(args.VariablesReference) })
+	case "evaluate":
+		var args struct {
+			Expression string `json:"expression"`
+		}
+		if e := json.Unmarshal(r.Arguments, &args); e != nil {
+			return nil, e
+		}
+		return s.inspect(func(stop debug.Stop) (any, error) {
+			value, e := stop.Interpreter.Evaluate(args.Expression)
+			if e != nil {
+				return nil, e
+			}
+			return map[string]any{"result": lox.Stringify(value), "variablesReference": 0}, nil
+		})
+
+	case "continue":
+		return map[string]any{"allThreadsContinued": true}, s.resume(command{mode: debug.Continue})
+	case "next":
+		return nil, s.resume(command{mode: debug.StepOver})
+	case "stepIn":
+		return nil, s.resume(command{mode: debug.StepIn})
+	case "stepOut":
+		return nil, s.resume(command{mode: debug.StepOut})
+
+	case "disconnect", "terminate":
+		s.abandon()
+		return nil, nil
+	}
+	return nil, fmt.Errorf("Unsupported request %s.", r.Command)
+}
+
+// run executes the program and reports how it ended.
+func (s *Server) run(ctx context.Context) {
+	e := s.machine.Run(ctx, s.source)
+	code := 0
+	if e != nil && !errors.Is(e, debug.ErrQuit) && !errors.Is(e, context.Canceled) {
+		code = s.machine.ReportError(e)
+		if code == 70 {
+			s.event("output", map[string]any{"category": "stderr", "output": "\n"})
+		}
+	}
+	s.event("exited", map[string]any{"exitCode": code})
+	s.event("terminated", nil)
+}
+
+// stop is the debugger's handler. It tells the client the program has
+// stopped, then serves commands until one resumes it.
+func (s *Server) stop(stop debug.Stop) (debug.Mode, error) {
+	s.mu.Lock()
+	s.stopped = true
+	s.handles = nil
+	s.mu.Unlock()
+	s.event("stopped", map[string]any{
+		"reason":            string(stop.Reason),
+		"threadId":          threadID,
+		"allThreadsStopped": true,
+	})
+
+	for c := range s.commands {
+		if c.inspect != nil {
+			body, e := c.inspect(stop)
+			c.done <- result{body, e}
+			continue
+		}
+		if c.quit {
+			return debug.Continue, debug.ErrQuit
+		}
+		return c.mode, nil
+	}
+	return debug.Continue, debug.ErrQuit
+}
+
+func (s *Server) isStopped() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stopped
+}
+
+// inspect runs f on the program's goroutine while it is stopped.
+func (s *Server) inspect(f func(stop debug.Stop) (any, error)) (any, error) {
+	if !s.isStopped() {
+		return nil, errors.New("The program is not stopped.")
+	}
+	done := make(chan result)
+	s.commands <- command{inspect: f, done: done}
+	r := <-done
+	return r.body, r.err
+}
+
+func (s *Server) resume(c command) error {
+	if !s.isStopped() {
+		return errors.New("The program is not stopped.")
+	}
+	s.mu.Lock()
+	s.stopped = false
+	s.mu.Unlock()
+	s.resumed = &c
+	return nil
+}
+
+// abandon ends the program, whether it is stopped or running.
+func (s *Server) abandon() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.mu.Lock()
+	stopped := s.stopped
+	s.stopped = false
+	s.mu.Unlock()
+	if stopped {
+		s.commands <- command{quit: true}
+	}
+}
+
+// statementLines returns the lines of file that have a statement, the
+// only ones the program can stop at.
+func statementLines(file string) map[int]bool {
+	source, e := os.ReadFile(file)
+	if e != nil {
+		return nil
+	}
+	scanner := lox.NewScanner([]rune(string(source)))
+	parser := lox.NewParser(scanner.ScanTokens())
+	// Coverage registers the same statements the debugger stops at.
+	c := coverage.New(file)
+	c.Instrument(parser.Parse())
+
+	lines := make(map[int]bool)
+	for _, line := range c.Lines() {
+		lines[line] = true
+	}
+	return lines
+}
+
+func sourceInfo(file string) map[string]any {
+	return map[string]any{"name": filepath.Base(file), "path": file}
+}
+
+// stackTrace lists the functions being executed, innermost first, with
+// top-level code last. Frame ids are positions in that list.
+func (s *Server) stackTrace(stop debug.Stop) (any, error) {
+	frames := make([]map[string]any, 0)
+	for n, frame := range stop.Interpreter.Stack(stop.Line) {
+		frames = append(frames, map[string]any{
+			"id": n, "name": frame.Function, "line": frame.Line, "column": 1, "source": sourceInfo(stop.Interpreter.FrameFile(n)),
+		})
+	}
+	frames = append(frames, map[string]any{
+		"id": len(frames), "name": "<script>", "line": stop.Interpreter.ScriptLine(stop.Line), "column": 1, "source": sourceInfo(stop.Interpreter.FrameFile(len(frames))),
+	})
+	return map[string]any{"stackFrames": frames, "totalFrames": len(frames)}, nil
+}
+
+// scopes offers one scope per environment level of a frame, innermost
+// first, then the globals.
+func (s *Server) scopes(stop debug.Stop, frame int) (any, error) {
+	if frame < 0 || frame > stop.Interpreter.Depth() {
+		return nil, fmt.Errorf("Unknown frame %d.", frame)
+	}
+	scopes := make([]map[string]any, 0)
+	level := 0
+	for scope := stop.Interpreter.FrameEnvironment(frame); scope != nil && scope.Enclosing() != nil; scope = scope.Enclosing() {
+		name := "Locals"
+		if level > 0 {
+			name = fmt.Sprintf("Enclosing %d", level)
+		}
+		scopes = append(scopes, map[string]any{"name": name, "variablesReference": s.reference(scope), "expensive": false})
+		level++
+	}
+	scopes = append(scopes, map[string]any{"name": "Globals", "variablesReference": s.reference(&stop.Interpreter.Globals), "expensive": false})
+	return map[string]any{"scopes": scopes}, nil
+}
+
+func (s *Server) reference(scope *env.Environment) int {
+	s.handles = append(s.handles, scope)
+	return len(s.handles)
+}
+
+func (s *Server) variables(reference int) (any, error) {
+	if reference < 1 || reference > len(s.handles) {
+		return nil, fmt.Errorf("Unknown variables reference %d.", reference)
+	}
+	scope := s.handles[reference-1]
+	variables := make([]map[string]any, 0)
+	for _, name := range scope.Names() {
+		value, _ := scope.Lookup(name)
+		if _, native := value.(*lox.NativeFunction); native {
+			continue
+		}
+		variables = append(variables, map[string]any{"name": name, "value": lox.Stringify(value), "variablesReference": 0})
+	}
+	return map[string]any{"variables": variables}, nil
+}
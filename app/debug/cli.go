@@ -0,0 +1,249 @@
+package debug
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/interpreter-starter-go/app/lox"
+)
+
+const cliHelp = `Commands:
+  c, continue       run until the next breakpoint
+  s, step           step into the next statement
+  n, next           step over function calls
+  o, out            run until the current function returns
+  b, break [file:]<line>
+                    set a breakpoint, in an imported file if given
+  d, delete [[file:]<line>]
+                    remove a breakpoint, or all of them
+  breakpoints       list breakpoints
+  bt, stack         print the call stack
+  p, print <expr>   evaluate an expression in the current scope
+  set <name> = <expr>
+                    assign to a variable
+  locals            list the variables in the enclosing scopes
+  globals           list the global variables
+  l, list           show the source around the current line
+  h, help           show this message
+  q, quit           abandon the program
+An empty line repeats the previous command.
+`
+
+// CLI is the interactive prompt behind the debug command. Install its
+// Hook on the VM that runs the program.
+type CLI struct {
+	*Debugger
+	// file is the program being debugged. sources holds the lines of it
+	// and of each imported file shown so far.
+	file    string
+	sources map[string][]string
+	in      *bufio.Scanner
+	out     io.Writer
+	last    string
+}
+
+// NewCLI debugs source, which was read from file.
+func NewCLI(file string, source string, in io.Reader, out io.Writer) *CLI {
+	if file != "" {
+		file, _ = filepath.Abs(file)
+	}
+	c := &CLI{
+		file:    file,
+		sources: map[string][]string{file: strings.Split(source, "\n")},
+		in:      bufio.NewScanner(in),
+		out:     out,
+	}
+	c.Debugger = New(c.stop)
+	return c
+}
+
+func (c *CLI) stop(stop Stop) (Mode, error) {
+	where := "<script>"
+	if stack := stop.Interpreter.Stack(stop.Line); len(stack) > 0 {
+		where = stack[0].Function
+	}
+	switch stop.Reason {
+	case ReasonEntry:
+		fmt.Fprintf(c.out, "Stopped at entry, %s in %s. Type help for commands.\n", c.location(stop.File, stop.Line), where)
+	case ReasonBreakpoint:
+		fmt.Fprintf(c.out, "Breakpoint at %s in %s.\n", c.location(stop.File, stop.Line), where)
+	default:
+		fmt.Fprintf(c.out, "%s in %s.\n", capitalize(c.location(stop.File, stop.Line)), where)
+	}
+	c.show(stop.File, stop.Line, stop.Line, stop.Line)
+
+	for {
+		fmt.Fprint(c.out, "(debug) ")
+		if !c.in.Scan() {
+			fmt.Fprintln(c.out)
+			return Continue, ErrQuit
+		}
+		line := strings.TrimSpace(c.in.Text())
+		if line == "" {
+			line = c.last
+		}
+		c.last = line
+
+		name, argument, _ := strings.Cut(line, " ")
+		argument = strings.TrimSpace(argument)
+		switch name {
+		case "":
+		case "c", "continue":
+			return Continue, nil
+		case "s", "step":
+			return StepIn, nil
+		case "n", "next":
+			return StepOver, nil
+		case "o", "out", "finish":
+			return StepOut, nil
+		case "q", "quit":
+			return Continue, ErrQuit
+		case "h", "help":
+			fmt.Fprint(c.out, cliHelp)
+		case "b", "break":
+			if file, n, ok := c.breakpoint(argument); ok {
+				c.SetBreakpoint(file, n)
+				fmt.Fprintf(c.out, "Breakpoint set at %s.\n", c.location(file, n))
+			}
+		case "d", "delete":
+			if argument == "" {
+				c.ClearBreakpoints()
+				fmt.Fprintln(c.out, "All breakpoints removed.")
+			} else if file, n, ok := c.breakpoint(argument); ok {
+				c.ClearBreakpoint(file, n)
+				fmt.Fprintf(c.out, "Breakpoint at %s removed.\n", c.location(file, n))
+			}
+		case "breakpoints":
+			for _, breakpoint := range c.Breakpoints() {
+				fmt.Fprintln(c.out, c.location(breakpoint.File, breakpoint.Line))
+			}
+		case "bt", "stack":
+			for n, frame := range stop.Interpreter.Stack(stop.Line) {
+				fmt.Fprintf(c.out, "#%d %s (line %d)\n", n, frame.Function, frame.Line)
+			}
+			fmt.Fprintf(c.out, "#%d <script>\n", len(stop.Interpreter.Stack(stop.Line)))
+		case "p", "print":
+			c.evaluate(stop.Interpreter, argument)
+		case "set":
+			if !strings.Contains(argument, "=") {
+				fmt.Fprintln(c.out, "Usage: set <name> = <expr>")
+				break
+			}
+			c.evaluate(stop.Interpreter, argument)
+		case "locals":
+			c.locals(stop.Interpreter)
+		case "globals":
+			c.globals(stop.Interpreter)
+		case "l", "list":
+			c.show(stop.File, stop.Line, stop.Line-5, stop.Line+5)
+		default:
+			fmt.Fprintf(c.out, "Unknown command %s. Type help for a list.\n", name)
+		}
+	}
+}
+
+// breakpoint parses a breakpoint argument: a line of the program, or of
+// another file when prefixed with its path and a colon. The path is
+// relative to the program's directory, as imports are.
+func (c *CLI) breakpoint(argument string) (string, int, bool) {
+	file := c.file
+	if colon := strings.LastIndex(argument, ":"); colon >= 0 {
+		file, argument = argument[:colon], argument[colon+1:]
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(filepath.Dir(c.file), file)
+		}
+		file = filepath.Clean(file)
+	}
+
+	lines, ok := c.lines(file)
+	if !ok {
+		fmt.Fprintf(c.out, "Can't read %s.\n", file)
+		return "", 0, false
+	}
+	n, e := strconv.Atoi(argument)
+	if e != nil || n < 1 || n > len(lines) {
+		fmt.Fprintf(c.out, "Expect a line number between 1 and %d.\n", len(lines))
+		return "", 0, false
+	}
+	return file, n, true
+}
+
+// lines returns the source lines of file, reading it the first time.
+func (c *CLI) lines(file string) ([]string, bool) {
+	if lines, ok := c.sources[file]; ok {
+		return lines, true
+	}
+	source, e := os.ReadFile(file)
+	if e != nil {
+		return nil, false
+	}
+	c.sources[file] = strings.Split(string(source), "\n")
+	return c.sources[file], true
+}
+
+// location names a line for messages. Lines outside the program also
+// name their file, relative to the program's directory.
+func (c *CLI) location(file string, line int) string {
+	if file == c.file {
+		return fmt.Sprintf("line %d", line)
+	}
+	if relative, e := filepath.Rel(filepath.Dir(c.file), file); e == nil {
+		file = relative
+	}
+	return fmt.Sprintf("line %d of %s", line, file)
+}
+
+func capitalize(s string) string {
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func (c *CLI) evaluate(i *lox.Interpreter, source string) {
+	value, e := i.Evaluate(source)
+	if e != nil {
+		fmt.Fprintln(c.out, e.Error())
+		return
+	}
+	fmt.Fprintln(c.out, lox.Stringify(value))
+}
+
+// locals prints each scope between the current one and the globals,
+// innermost first.
+func (c *CLI) locals(i *lox.Interpreter) {
+	for scope := i.Environment(); scope != nil && scope.Enclosing() != nil; scope = scope.Enclosing() {
+		for _, name := range scope.Names() {
+			value, _ := scope.Lookup(name)
+			fmt.Fprintf(c.out, "%s = %s\n", name, lox.Stringify(value))
+		}
+	}
+}
+
+func (c *CLI) globals(i *lox.Interpreter) {
+	for _, name := range i.Globals.Names() {
+		value, _ := i.Globals.Lookup(name)
+		if _, native := value.(*lox.NativeFunction); native {
+			continue
+		}
+		fmt.Fprintf(c.out, "%s = %s\n", name, lox.Stringify(value))
+	}
+}
+
+// show prints the lines of file from first to last, marking the current
+// line and breakpoints.
+func (c *CLI) show(file string, current int, first int, last int) {
+	lines, _ := c.lines(file)
+	for n := max(first, 1); n <= min(last, len(lines)); n++ {
+		marker := "  "
+		if n == current {
+			marker = "->"
+		}
+		if c.HasBreakpoint(file, n) {
+			marker = marker[:1] + "*"
+		}
+		fmt.Fprintf(c.out, "%s %4d | %s\n", marker, n, lines[n-1])
+	}
+}
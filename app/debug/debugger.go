@@ -0,0 +1,219 @@
+// Package debug drives the tree-walking interpreter one statement at a
+// time. Debugger holds the breakpoints and stepping state; front ends such
+// as the command line prompt decide what to do when it stops.
+package debug
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/codecrafters-io/interpreter-starter-go/app/lox"
+	st "github.com/codecrafters-io/interpreter-starter-go/app/stmt"
+)
+
+// ErrQuit is returned from Run when the user abandons the program.
+var ErrQuit = errors.New("debugger quit")
+
+// Mode says how far the program runs before stopping again.
+type Mode int
+
+const (
+	// Continue runs until a breakpoint.
+	Continue Mode = iota
+	// StepIn stops at the next statement, inside a called function if
+	// there is one.
+	StepIn
+	// StepOver stops at the next statement in the same function or a
+	// caller.
+	StepOver
+	// StepOut stops once the current function has returned.
+	StepOut
+)
+
+// Reason says why the program stopped.
+type Reason string
+
+const (
+	ReasonEntry      Reason = "entry"
+	ReasonBreakpoint Reason = "breakpoint"
+	ReasonStep       Reason = "step"
+)
+
+// Stop describes where the program is paused. It is only valid until the
+// stop handler returns.
+type Stop struct {
+	Reason Reason
+	// File is the absolute path of the file being executed, empty if the
+	// program was not read from one.
+	File        string
+	Line        int
+	Statement   st.Stmt
+	Interpreter *lox.Interpreter
+}
+
+// Handler is called whenever the program stops. It returns how to carry
+// on, or an error to abandon the program.
+type Handler func(stop Stop) (Mode, error)
+
+// Breakpoint is a line in a file, named by its absolute path.
+type Breakpoint struct {
+	File string
+	Line int
+}
+
+type Debugger struct {
+	handler Handler
+	// mu guards breakpoints, which front ends may change while the
+	// program runs.
+	mu          sync.Mutex
+	breakpoints map[Breakpoint]bool
+	entry       bool
+	mode        Mode
+	// file, line and depth are where the last step started.
+	file  string
+	line  int
+	depth int
+	// runs holds, for each call depth, the line being executed there and
+	// the statements run on it since it was reached, so a breakpoint on a
+	// line with several statements stops once per pass over the line.
+	runs []run
+}
+
+// run is a stretch of statements executed on one line in one frame.
+type run struct {
+	file       string
+	line       int
+	statements map[st.Stmt]bool
+}
+
+// New returns a debugger that stops before the first statement.
+func New(handler Handler) *Debugger {
+	return &Debugger{
+		handler:     handler,
+		breakpoints: make(map[Breakpoint]bool),
+		entry:       true,
+		mode:        StepIn,
+	}
+}
+
+// SetStopOnEntry chooses whether the program stops before its first
+// statement or runs straight to the first breakpoint. Call it before the
+// program starts.
+func (d *Debugger) SetStopOnEntry(stop bool) {
+	d.entry = stop
+	d.mode = StepIn
+	if !stop {
+		d.mode = Continue
+	}
+}
+
+func (d *Debugger) SetBreakpoint(file string, line int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.breakpoints[Breakpoint{file, line}] = true
+}
+
+func (d *Debugger) ClearBreakpoint(file string, line int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.breakpoints, Breakpoint{file, line})
+}
+
+// ClearBreakpoints removes every breakpoint.
+func (d *Debugger) ClearBreakpoints() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.breakpoints = make(map[Breakpoint]bool)
+}
+
+// ClearFileBreakpoints removes the breakpoints in file.
+func (d *Debugger) ClearFileBreakpoints(file string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for breakpoint := range d.breakpoints {
+		if breakpoint.File == file {
+			delete(d.breakpoints, breakpoint)
+		}
+	}
+}
+
+func (d *Debugger) HasBreakpoint(file string, line int) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.breakpoints[Breakpoint{file, line}]
+}
+
+// Breakpoints returns the breakpoints, ordered by file and then line.
+func (d *Debugger) Breakpoints() []Breakpoint {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	breakpoints := make([]Breakpoint, 0, len(d.breakpoints))
+	for breakpoint := range d.breakpoints {
+		breakpoints = append(breakpoints, breakpoint)
+	}
+	sort.Slice(breakpoints, func(a, b int) bool {
+		if breakpoints[a].File != breakpoints[b].File {
+			return breakpoints[a].File < breakpoints[b].File
+		}
+		return breakpoints[a].Line < breakpoints[b].Line
+	})
+	return breakpoints
+}
+
+// Hook is installed as lox.Options.Hook.
+func (d *Debugger) Hook(i *lox.Interpreter, stmt st.Stmt) error {
+	// A block is not a step of its own; its statements are.
+	if _, ok := stmt.(*st.Block); ok {
+		return nil
+	}
+
+	file := i.FrameFile(0)
+	line := st.Span(stmt).Line
+	depth := i.Depth()
+	reached := d.reach(depth, file, line, stmt)
+
+	reason := Reason("")
+	switch {
+	case d.entry:
+		reason = ReasonEntry
+		d.entry = false
+	case reached && d.HasBreakpoint(file, line):
+		reason = ReasonBreakpoint
+	case d.mode == StepIn && (file != d.file || line != d.line || depth != d.depth):
+		reason = ReasonStep
+	case d.mode == StepOver && (depth < d.depth || depth == d.depth && (file != d.file || line != d.line)):
+		reason = ReasonStep
+	case d.mode == StepOut && depth < d.depth:
+		reason = ReasonStep
+	}
+	if reason == "" {
+		return nil
+	}
+
+	mode, e := d.handler(Stop{Reason: reason, File: file, Line: line, Statement: stmt, Interpreter: i})
+	if e != nil {
+		return e
+	}
+	d.mode, d.file, d.line, d.depth = mode, file, line, depth
+	return nil
+}
+
+// reach records that stmt, on line of file, is about to run at depth, and
+// reports whether this starts a new pass over the line: the frame has
+// just come to the line, or is back at a statement it already ran there,
+// as at the top of each loop iteration.
+func (d *Debugger) reach(depth int, file string, line int, stmt st.Stmt) bool {
+	for len(d.runs) <= depth {
+		d.runs = append(d.runs, run{})
+	}
+	d.runs = d.runs[:depth+1]
+
+	r := &d.runs[depth]
+	if r.file == file && r.line == line && !r.statements[stmt] {
+		r.statements[stmt] = true
+		return false
+	}
+	*r = run{file: file, line: line, statements: map[st.Stmt]bool{stmt: true}}
+	return true
+}
@@ -2,6 +2,7 @@ package environment
 
 import (
 	"fmt"
+	"sort"
 
 	err "github.com/codecrafters-io/interpreter-starter-go/app/err"
 	tok "github.com/codecrafters-io/interpreter-starter-go/app/token"
@@ -59,6 +60,37 @@ func (e *Environment) AssignAt(distance int, name tok.Token, value any) {
 	e.ancestor(distance).Assign(name, value)
 }
 
+func (e *Environment) Enclosing() *Environment {
+	return e.enclosing
+}
+
+// Root returns the outermost environment, which holds the globals of the
+// module that e belongs to.
+func (e *Environment) Root() *Environment {
+	root := e
+	for root.enclosing != nil {
+		root = root.enclosing
+	}
+	return root
+}
+
+// Names lists the variables defined directly in this environment, sorted.
+func (e *Environment) Names() []string {
+	names := make([]string, 0, len(e.values))
+	for name := range e.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lookup returns the value of a variable defined directly in this
+// environment.
+func (e *Environment) Lookup(name string) (any, bool) {
+	value, ok := e.values[name]
+	return value, ok
+}
+
 func (e *Environment) Define(name string, value any) {
 	e.values[name] = value
 }
@@ -2,13 +2,32 @@ package err
 
 import (
 	"fmt"
+	"strings"
 
 	tok "github.com/codecrafters-io/interpreter-starter-go/app/token"
 )
 
+// maxTraceFrames caps how much of a traceback is printed, so a runaway
+// recursion doesn't bury the error under thousands of identical lines.
+const maxTraceFrames = 32
+
+// Frame is one entry in a traceback: a function that was executing when the
+// error happened and the line it had reached.
+type Frame struct {
+	Function string
+	Line     int
+}
+
 type RuntimeError struct {
 	message string
 	token   tok.Token
+	// Trace lists the active Lox functions, innermost first. It is empty
+	// when the error happened in top-level code.
+	Trace []Frame
+	// value is what a throw statement raised. thrown tells a thrown nil
+	// apart from an error the interpreter raised itself.
+	value  any
+	thrown bool
 }
 
 func NewRuntimeError(token tok.Token, message string) *RuntimeError {
@@ -18,15 +37,60 @@ func NewRuntimeError(token tok.Token, message string) *RuntimeError {
 	}
 }
 
+// NewThrownError wraps a value raised by a throw statement. message
+// describes the value for when nothing catches it.
+func NewThrownError(token tok.Token, message string, value any) *RuntimeError {
+	return &RuntimeError{
+		message: message,
+		token:   token,
+		value:   value,
+		thrown:  true,
+	}
+}
+
+// Thrown returns the value a throw statement raised, or false if the
+// interpreter raised the error itself.
+func (e *RuntimeError) Thrown() (any, bool) {
+	return e.value, e.thrown
+}
+
+func (e *RuntimeError) Line() int {
+	return e.token.Line
+}
+
+// Message is the error without its location or traceback.
+func (e *RuntimeError) Message() string {
+	return e.message
+}
+
+// Span locates the token the error was reported at.
+func (e *RuntimeError) Span() tok.Span {
+	return e.token.Span()
+}
+
 func (e *RuntimeError) Error() string {
+	var b strings.Builder
 	if len(e.token.Lexeme) > 0 {
-		return fmt.Sprintf("[line %d] Error at '%s': %s",
+		fmt.Fprintf(&b, "[line %d] Error at '%s': %s",
 			e.token.Line,
 			string(e.token.Lexeme),
 			e.message)
+	} else {
+		fmt.Fprintf(&b, "[line %d] Error: %s", e.token.Line, e.message)
+	}
+
+	if len(e.Trace) == 0 {
+		return b.String()
 	}
-	return fmt.Sprintf("[line %d] Error: %s", e.token.Line, e.message)
+	for n, frame := range e.Trace {
+		if n == maxTraceFrames {
+			fmt.Fprintf(&b, "\n ... %d more", len(e.Trace)-n)
+			break
+		}
+		fmt.Fprintf(&b, "\n at %s (line %d)", frame.Function, frame.Line)
+	}
+	b.WriteString("\n at <script>")
+	return b.String()
 }
 
 var _ error = (*RuntimeError)(nil)
-
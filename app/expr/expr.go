@@ -14,6 +14,15 @@ type ExprVisitor interface {
 	VisitVariableExpr(expr *Variable) any
 	VisitAssignExpr(expr *Assign) any
 	VisitLogicalExpr(expr *Logical) any
+	VisitGetExpr(expr *Get) any
+	VisitSetExpr(expr *Set) any
+	VisitThisExpr(expr *This) any
+	VisitSuperExpr(expr *Super) any
+	VisitListExpr(expr *List) any
+	VisitIndexExpr(expr *Index) any
+	VisitIndexSetExpr(expr *IndexSet) any
+	VisitMapExpr(expr *Map) any
+	VisitFunctionExpr(expr *Function) any
 }
 
 // EXPR
@@ -36,6 +45,7 @@ var _ Expr = (*Binary)(nil)
 
 // LITERAL EXPR
 type Literal struct {
+	Token token.Token
 	Value interface{}
 }
 
@@ -59,7 +69,9 @@ func (u *Unary) Accept(visitor ExprVisitor) interface{} {
 var _ Expr = (*Unary)(nil)
 
 type Grouping struct {
+	Start      token.Token
 	Expression Expr
+	Paren      token.Token
 }
 
 func (g *Grouping) Accept(visitor ExprVisitor) interface{} {
@@ -123,3 +135,118 @@ func (c *Call) Accept(visitor ExprVisitor) interface{} {
 }
 
 var _ Expr = (*Call)(nil)
+
+type Get struct {
+	Object Expr
+	Name   token.Token
+}
+
+func (g *Get) Accept(visitor ExprVisitor) interface{} {
+	return visitor.VisitGetExpr(g)
+}
+
+var _ Expr = (*Get)(nil)
+
+type Set struct {
+	Object Expr
+	Name   token.Token
+	Value  Expr
+}
+
+func (s *Set) Accept(visitor ExprVisitor) interface{} {
+	return visitor.VisitSetExpr(s)
+}
+
+var _ Expr = (*Set)(nil)
+
+type This struct {
+	Keyword token.Token
+}
+
+func (t *This) Accept(visitor ExprVisitor) interface{} {
+	return visitor.VisitThisExpr(t)
+}
+
+var _ Expr = (*This)(nil)
+
+type Super struct {
+	Keyword token.Token
+	Method  token.Token
+}
+
+func (s *Super) Accept(visitor ExprVisitor) interface{} {
+	return visitor.VisitSuperExpr(s)
+}
+
+var _ Expr = (*Super)(nil)
+
+type List struct {
+	Start    token.Token
+	Bracket  token.Token
+	Elements []Expr
+}
+
+func (l *List) Accept(visitor ExprVisitor) interface{} {
+	return visitor.VisitListExpr(l)
+}
+
+var _ Expr = (*List)(nil)
+
+type Index struct {
+	Object  Expr
+	Bracket token.Token
+	Index   Expr
+}
+
+func (i *Index) Accept(visitor ExprVisitor) interface{} {
+	return visitor.VisitIndexExpr(i)
+}
+
+var _ Expr = (*Index)(nil)
+
+type IndexSet struct {
+	Object  Expr
+	Bracket token.Token
+	Index   Expr
+	Value   Expr
+}
+
+func (i *IndexSet) Accept(visitor ExprVisitor) interface{} {
+	return visitor.VisitIndexSetExpr(i)
+}
+
+var _ Expr = (*IndexSet)(nil)
+
+type Map struct {
+	Start  token.Token
+	Brace  token.Token
+	Keys   []Expr
+	Values []Expr
+}
+
+func (m *Map) Accept(visitor ExprVisitor) interface{} {
+	return visitor.VisitMapExpr(m)
+}
+
+var _ Expr = (*Map)(nil)
+
+// Function is an anonymous function, either "fun (a) { ... }" or an arrow
+// function "(a) => a * 2". An arrow function whose body is an expression
+// gets a body of one return statement with the arrow as its keyword.
+type Function struct {
+	// Keyword is the fun keyword, or the opening parenthesis of an arrow
+	// function.
+	Keyword token.Token
+	// Declaration is the *stmt.Function, named "anonymous", holding the
+	// parameters and body. It can't be typed here because package stmt
+	// imports this one.
+	Declaration any
+	// End is the function's last token.
+	End token.Token
+}
+
+func (f *Function) Accept(visitor ExprVisitor) interface{} {
+	return visitor.VisitFunctionExpr(f)
+}
+
+var _ Expr = (*Function)(nil)
@@ -0,0 +1,48 @@
+package expr
+
+import (
+	token "github.com/codecrafters-io/interpreter-starter-go/app/token"
+)
+
+// Span returns the source range covered by an expression, from its first
+// token to its last.
+func Span(e Expr) token.Span {
+	switch e := e.(type) {
+	case *Binary:
+		return Span(e.Left).Join(Span(e.Right))
+	case *Logical:
+		return Span(e.Left).Join(Span(e.Right))
+	case *Literal:
+		return e.Token.Span()
+	case *Unary:
+		return e.Operator.Span().Join(Span(e.Right))
+	case *Grouping:
+		return e.Start.Span().Join(e.Paren.Span())
+	case *Variable:
+		return e.Name.Span()
+	case *Assign:
+		return e.Name.Span().Join(Span(e.Value))
+	case *Call:
+		return Span(e.Callee).Join(e.Paren.Span())
+	case *Get:
+		return Span(e.Object).Join(e.Name.Span())
+	case *Set:
+		return Span(e.Object).Join(Span(e.Value))
+	case *This:
+		return e.Keyword.Span()
+	case *Super:
+		return e.Keyword.Span().Join(e.Method.Span())
+	case *List:
+		return e.Start.Span().Join(e.Bracket.Span())
+	case *Map:
+		return e.Start.Span().Join(e.Brace.Span())
+	case *Index:
+		return Span(e.Object).Join(e.Bracket.Span())
+	case *IndexSet:
+		return Span(e.Object).Join(Span(e.Value))
+	case *Function:
+		return e.Keyword.Span().Join(e.End.Span())
+	default:
+		return token.Span{}
+	}
+}
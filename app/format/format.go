@@ -0,0 +1,497 @@
+// Package format re-emits Lox source in a canonical layout: two-space
+// indentation, spaces around binary operators, opening braces on the same
+// line and one statement per line. Comments are carried over.
+package format
+
+import (
+	"sort"
+	"strings"
+
+	exp "github.com/codecrafters-io/interpreter-starter-go/app/expr"
+	"github.com/codecrafters-io/interpreter-starter-go/app/lox"
+	st "github.com/codecrafters-io/interpreter-starter-go/app/stmt"
+	tok "github.com/codecrafters-io/interpreter-starter-go/app/token"
+)
+
+const indentation = "  "
+
+// Source formats a program. Nothing is formatted if the source does not
+// scan and parse cleanly; the syntax errors are returned instead.
+func Source(source []rune) (string, []*lox.Diagnostic) {
+	scanner := lox.NewScanner(source)
+	tokens := scanner.ScanTokens()
+	parser := lox.NewParser(tokens)
+	statements := parser.Parse()
+	if scanner.HadError() || parser.HadError() {
+		return "", append(scanner.Errors(), parser.Errors()...)
+	}
+
+	f := &formatter{
+		tokens:   tokens[:len(tokens)-1],
+		comments: scanner.Comments(),
+		b:        &strings.Builder{},
+	}
+	f.sequence(statements, tokens[len(tokens)-1].Offset, f.stmt)
+	return f.b.String(), nil
+}
+
+type formatter struct {
+	// tokens are the program's tokens without the final EOF, used to find
+	// where each statement really starts and ends.
+	tokens   []tok.Token
+	comments []tok.Token
+	// next is the first comment not yet written.
+	next   int
+	indent int
+	// b is where statements are written. Expressions are returned as
+	// strings, so anonymous functions swap in a builder of their own for
+	// their bodies.
+	b *strings.Builder
+}
+
+// sequence writes statements one per line at the current indentation,
+// followed by any comments left before offset end. A single blank line
+// is kept wherever the source had one or more.
+func (f *formatter) sequence(statements []st.Stmt, end int, write func(st.Stmt)) {
+	last := -1
+	for i, statement := range statements {
+		first := f.first(statement)
+		boundary := end
+		if i+1 < len(statements) {
+			boundary = f.first(statements[i+1]).Offset
+		}
+
+		last = f.flush(first.Offset, last)
+		f.blank(first.Span().Line, last)
+		f.writeIndent()
+		write(statement)
+
+		// Comments inside the statement can't stay where they were, so
+		// they follow it along with the one after it on its last line.
+		final := f.lastToken(boundary)
+		last = final.Line
+		wrote := f.trailing(final.Offset, false)
+		if f.next < len(f.comments) {
+			if c := f.comments[f.next]; c.Line == last && c.Offset < boundary {
+				f.trailing(c.Offset+1, wrote)
+			}
+		}
+		f.b.WriteString("\n")
+	}
+	f.flush(end, last)
+}
+
+// flush writes the pending comments that start before offset, each on its
+// own line, and returns the line of the last one written.
+func (f *formatter) flush(offset int, last int) int {
+	for f.next < len(f.comments) && f.comments[f.next].Offset < offset {
+		c := f.comments[f.next]
+		f.blank(c.Line, last)
+		f.writeIndent()
+		f.b.WriteString(string(c.Lexeme) + "\n")
+		last = c.Line
+		f.next++
+	}
+	return last
+}
+
+// trailing writes the pending comments that start before offset after
+// the code on the current line: the first as a trailing comment and any
+// others on lines of their own. wrote says whether the line already has
+// one, and the result whether it has one now.
+func (f *formatter) trailing(offset int, wrote bool) bool {
+	for f.next < len(f.comments) && f.comments[f.next].Offset < offset {
+		if wrote {
+			f.b.WriteString("\n")
+			f.writeIndent()
+		} else {
+			f.b.WriteString(" ")
+		}
+		f.b.WriteString(string(f.comments[f.next].Lexeme))
+		f.next++
+		wrote = true
+	}
+	return wrote
+}
+
+func (f *formatter) blank(line int, last int) {
+	if last >= 0 && line > last+1 {
+		f.b.WriteString("\n")
+	}
+}
+
+func (f *formatter) writeIndent() {
+	f.b.WriteString(strings.Repeat(indentation, f.indent))
+}
+
+// first returns a statement's first token. Spans of functions and classes
+// begin at the name, so the keyword in front of it is looked up.
+func (f *formatter) first(statement st.Stmt) tok.Token {
+	offset := st.Span(statement).Offset
+	n := sort.Search(len(f.tokens), func(i int) bool { return f.tokens[i].Offset >= offset })
+	if n > 0 {
+		switch statement.(type) {
+		case *st.Function, *st.Class:
+			if t := f.tokens[n-1].Type; t == tok.FUN || t == tok.CLASS {
+				n--
+			}
+		}
+	}
+	if n == len(f.tokens) {
+		return tok.Token{Offset: offset}
+	}
+	return f.tokens[n]
+}
+
+// lastToken returns the last token before offset.
+func (f *formatter) lastToken(offset int) tok.Token {
+	n := sort.Search(len(f.tokens), func(i int) bool { return f.tokens[i].Offset >= offset })
+	if n == 0 {
+		return tok.Token{}
+	}
+	return f.tokens[n-1]
+}
+
+// opening returns the brace matched by the closing brace at offset end.
+func (f *formatter) opening(end int) tok.Token {
+	depth := 0
+	n := sort.Search(len(f.tokens), func(i int) bool { return f.tokens[i].Offset >= end })
+	for n = min(n, len(f.tokens)-1); n >= 0; n-- {
+		switch f.tokens[n].Type {
+		case tok.RIGHT_BRACE:
+			depth++
+		case tok.LEFT_BRACE:
+			depth--
+			if depth == 0 {
+				return f.tokens[n]
+			}
+		}
+	}
+	return tok.Token{Offset: end}
+}
+
+// block writes a braced list of statements closed by the brace at offset
+// end. Comments left before the opening brace, such as one in front of an
+// else, trail it, as do those on the brace's line before the first
+// statement. A block with nothing in it stays on one line.
+func (f *formatter) block(statements []st.Stmt, end int, write func(st.Stmt)) {
+	f.b.WriteString("{")
+	f.indent++
+	open := f.opening(end)
+	wrote := f.trailing(open.Offset, false)
+	before := end
+	if len(statements) > 0 {
+		before = f.first(statements[0]).Offset
+	}
+	for f.next < len(f.comments) {
+		c := f.comments[f.next]
+		if c.Line != open.Line || c.Offset >= before {
+			break
+		}
+		wrote = f.trailing(c.Offset+1, wrote)
+	}
+	if !wrote && len(statements) == 0 && (f.next == len(f.comments) || f.comments[f.next].Offset >= end) {
+		f.indent--
+		f.b.WriteString("}")
+		return
+	}
+	f.b.WriteString("\n")
+	f.sequence(statements, end, write)
+	f.indent--
+	f.writeIndent()
+	f.b.WriteString("}")
+}
+
+func (f *formatter) stmt(statement st.Stmt) {
+	statement.Accept(f)
+}
+
+func (f *formatter) method(statement st.Stmt) {
+	f.function(statement.(*st.Function))
+}
+
+func (f *formatter) function(function *st.Function) {
+	params := make([]string, len(function.Params))
+	for i, param := range function.Params {
+		params[i] = string(param.Lexeme)
+	}
+	f.b.WriteString(string(function.Name.Lexeme) + "(" + strings.Join(params, ", ") + ") ")
+	f.block(function.Body, function.End.Offset, f.stmt)
+}
+
+// isBlock reports whether a statement is a block the user wrote, rather
+// than one the parser made up while desugaring a for loop.
+func isBlock(statement st.Stmt) bool {
+	block, ok := statement.(*st.Block)
+	return ok && block.Brace.Type == tok.LEFT_BRACE
+}
+
+// branch writes the body of an if, while or for.
+func (f *formatter) branch(body st.Stmt) {
+	f.b.WriteString(" ")
+	f.stmt(body)
+}
+
+// forLoop puts back the for loop the parser desugared into an optional
+// initializer followed by a while loop that runs the increment.
+func (f *formatter) forLoop(initializer st.Stmt, loop *st.While) {
+	f.b.WriteString("for (")
+	if initializer == nil {
+		f.b.WriteString(";")
+	} else {
+		f.stmt(initializer)
+	}
+
+	if literal, ok := loop.Condition.(*exp.Literal); !ok || literal.Token.Type != tok.FOR {
+		f.b.WriteString(" " + f.expr(loop.Condition))
+	}
+	f.b.WriteString(";")
+
+	if loop.Increment != nil {
+		f.b.WriteString(" " + f.expr(loop.Increment))
+	}
+	f.b.WriteString(")")
+	f.branch(loop.Body)
+}
+
+func (f *formatter) VisitExpressionStmt(stmt *st.Expression) any {
+	f.b.WriteString(f.expr(stmt.Expression) + ";")
+	return nil
+}
+
+func (f *formatter) VisitPrintStmt(stmt *st.Print) any {
+	f.b.WriteString("print " + f.expr(stmt.Expression) + ";")
+	return nil
+}
+
+func (f *formatter) VisitVarStmt(stmt *st.Var) any {
+	f.b.WriteString("var " + string(stmt.Name.Lexeme))
+	if stmt.Initializer != nil {
+		f.b.WriteString(" = " + f.expr(stmt.Initializer))
+	}
+	f.b.WriteString(";")
+	return nil
+}
+
+func (f *formatter) VisitBlockStmt(stmt *st.Block) any {
+	if stmt.Brace.Type == tok.FOR {
+		f.forLoop(stmt.Statements[0], stmt.Statements[1].(*st.While))
+		return nil
+	}
+	f.block(stmt.Statements, stmt.End.Offset, f.stmt)
+	return nil
+}
+
+func (f *formatter) VisitIfStmt(stmt *st.If) any {
+	f.b.WriteString("if (" + f.expr(stmt.Condition) + ")")
+	f.branch(stmt.ThenBranch)
+	if stmt.ElseBranch == nil {
+		return nil
+	}
+	if isBlock(stmt.ThenBranch) {
+		f.b.WriteString(" else")
+	} else {
+		f.b.WriteString("\n")
+		f.writeIndent()
+		f.b.WriteString("else")
+	}
+	f.branch(stmt.ElseBranch)
+	return nil
+}
+
+func (f *formatter) VisitWhileStmt(stmt *st.While) any {
+	if stmt.Keyword.Type == tok.FOR {
+		f.forLoop(nil, stmt)
+		return nil
+	}
+	f.b.WriteString("while (" + f.expr(stmt.Condition) + ")")
+	f.branch(stmt.Body)
+	return nil
+}
+
+func (f *formatter) VisitFunctionStmt(stmt *st.Function) any {
+	f.b.WriteString("fun ")
+	f.function(stmt)
+	return nil
+}
+
+func (f *formatter) VisitReturnStmt(stmt *st.Return) any {
+	if stmt.Value == nil {
+		f.b.WriteString("return;")
+	} else {
+		f.b.WriteString("return " + f.expr(stmt.Value) + ";")
+	}
+	return nil
+}
+
+func (f *formatter) VisitBreakStmt(stmt *st.Break) any {
+	f.b.WriteString("break;")
+	return nil
+}
+
+func (f *formatter) VisitContinueStmt(stmt *st.Continue) any {
+	f.b.WriteString("continue;")
+	return nil
+}
+
+func (f *formatter) VisitThrowStmt(stmt *st.Throw) any {
+	f.b.WriteString("throw " + f.expr(stmt.Value) + ";")
+	return nil
+}
+
+func (f *formatter) VisitTryStmt(stmt *st.Try) any {
+	f.b.WriteString("try ")
+	f.stmt(stmt.Body)
+	if stmt.Catch != nil {
+		f.b.WriteString(" catch (" + string(stmt.CatchName.Lexeme) + ") ")
+		f.stmt(stmt.Catch)
+	}
+	if stmt.Finally != nil {
+		f.b.WriteString(" finally ")
+		f.stmt(stmt.Finally)
+	}
+	return nil
+}
+
+func (f *formatter) VisitImportStmt(stmt *st.Import) any {
+	if len(stmt.Names) == 0 {
+		f.b.WriteString("import " + string(stmt.Path.Lexeme) + " as " + string(stmt.Alias.Lexeme) + ";")
+		return nil
+	}
+	names := make([]string, len(stmt.Names))
+	for i, name := range stmt.Names {
+		names[i] = string(name.Lexeme)
+	}
+	f.b.WriteString("from " + string(stmt.Path.Lexeme) + " import " + strings.Join(names, ", ") + ";")
+	return nil
+}
+
+func (f *formatter) VisitClassStmt(stmt *st.Class) any {
+	f.b.WriteString("class " + string(stmt.Name.Lexeme) + " ")
+	if stmt.Superclass != nil {
+		f.b.WriteString("< " + string(stmt.Superclass.Name.Lexeme) + " ")
+	}
+	methods := make([]st.Stmt, len(stmt.Methods))
+	for i, method := range stmt.Methods {
+		methods[i] = method
+	}
+	f.block(methods, stmt.End.Offset, f.method)
+	return nil
+}
+
+// Expression visitor
+
+func (f *formatter) expr(expr exp.Expr) string {
+	return expr.Accept(f).(string)
+}
+
+func (f *formatter) list(exprs []exp.Expr) string {
+	parts := make([]string, len(exprs))
+	for i, expr := range exprs {
+		parts[i] = f.expr(expr)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (f *formatter) VisitBinaryExpr(expr *exp.Binary) any {
+	return f.expr(expr.Left) + " " + string(expr.Operator.Lexeme) + " " + f.expr(expr.Right)
+}
+
+func (f *formatter) VisitLogicalExpr(expr *exp.Logical) any {
+	return f.expr(expr.Left) + " " + string(expr.Operator.Lexeme) + " " + f.expr(expr.Right)
+}
+
+func (f *formatter) VisitGroupingExpr(expr *exp.Grouping) any {
+	return "(" + f.expr(expr.Expression) + ")"
+}
+
+// VisitLiteralExpr writes literals as they were spelled, so 1.50 is not
+// turned into 1.5.
+func (f *formatter) VisitLiteralExpr(expr *exp.Literal) any {
+	return string(expr.Token.Lexeme)
+}
+
+func (f *formatter) VisitUnaryExpr(expr *exp.Unary) any {
+	return string(expr.Operator.Lexeme) + f.expr(expr.Right)
+}
+
+func (f *formatter) VisitCallExpr(expr *exp.Call) any {
+	return f.expr(expr.Callee) + "(" + f.list(expr.Arguments) + ")"
+}
+
+func (f *formatter) VisitVariableExpr(expr *exp.Variable) any {
+	return string(expr.Name.Lexeme)
+}
+
+func (f *formatter) VisitAssignExpr(expr *exp.Assign) any {
+	return string(expr.Name.Lexeme) + " = " + f.expr(expr.Value)
+}
+
+func (f *formatter) VisitGetExpr(expr *exp.Get) any {
+	return f.expr(expr.Object) + "." + string(expr.Name.Lexeme)
+}
+
+func (f *formatter) VisitSetExpr(expr *exp.Set) any {
+	return f.expr(expr.Object) + "." + string(expr.Name.Lexeme) + " = " + f.expr(expr.Value)
+}
+
+func (f *formatter) VisitThisExpr(expr *exp.This) any {
+	return "this"
+}
+
+func (f *formatter) VisitSuperExpr(expr *exp.Super) any {
+	return "super." + string(expr.Method.Lexeme)
+}
+
+func (f *formatter) VisitListExpr(expr *exp.List) any {
+	return "[" + f.list(expr.Elements) + "]"
+}
+
+func (f *formatter) VisitMapExpr(expr *exp.Map) any {
+	entries := make([]string, len(expr.Keys))
+	for i, key := range expr.Keys {
+		entries[i] = f.expr(key) + ": " + f.expr(expr.Values[i])
+	}
+	return "{" + strings.Join(entries, ", ") + "}"
+}
+
+func (f *formatter) VisitIndexExpr(expr *exp.Index) any {
+	return f.expr(expr.Object) + "[" + f.expr(expr.Index) + "]"
+}
+
+func (f *formatter) VisitIndexSetExpr(expr *exp.IndexSet) any {
+	return f.expr(expr.Object) + "[" + f.expr(expr.Index) + "] = " + f.expr(expr.Value)
+}
+
+// VisitFunctionExpr writes the function's body indented one level deeper
+// than the statement it appears in. An arrow function whose body is one
+// expression stays on one line.
+func (f *formatter) VisitFunctionExpr(expr *exp.Function) any {
+	declaration := expr.Declaration.(*st.Function)
+	params := make([]string, len(declaration.Params))
+	for i, param := range declaration.Params {
+		params[i] = string(param.Lexeme)
+	}
+
+	arrow := expr.Keyword.Type == tok.LEFT_PAREN
+	if arrow && len(declaration.Body) == 1 {
+		if body, ok := declaration.Body[0].(*st.Return); ok && body.Keyword.Type == tok.ARROW {
+			return "(" + strings.Join(params, ", ") + ") => " + f.expr(body.Value)
+		}
+	}
+
+	outer := f.b
+	f.b = &strings.Builder{}
+	if arrow {
+		f.b.WriteString("(" + strings.Join(params, ", ") + ") => ")
+	} else {
+		f.b.WriteString("fun (" + strings.Join(params, ", ") + ") ")
+	}
+	f.block(declaration.Body, declaration.End.Offset, f.stmt)
+	function := f.b.String()
+	f.b = outer
+	return function
+}
+
+var _ exp.ExprVisitor = (*formatter)(nil)
+var _ st.StmtVisitor = (*formatter)(nil)
@@ -0,0 +1,201 @@
+package lox
+
+import (
+	"sort"
+
+	st "github.com/codecrafters-io/interpreter-starter-go/app/stmt"
+	tok "github.com/codecrafters-io/interpreter-starter-go/app/token"
+)
+
+// SymbolKind says what declared a Symbol.
+type SymbolKind int
+
+const (
+	SymbolVariable SymbolKind = iota
+	SymbolParameter
+	SymbolFunction
+	SymbolClass
+	SymbolMethod
+	SymbolBuiltin
+)
+
+// Symbol is a name declared in a program, along with every place it is
+// used.
+type Symbol struct {
+	Name tok.Token
+	Kind SymbolKind
+	// Params are a function's or method's parameters. A class takes the
+	// parameters of its init method.
+	Params []tok.Token
+	// Arity is the number of arguments a callable symbol takes, and -1
+	// for everything else.
+	Arity int
+	// Container names the class a method belongs to.
+	Container string
+	// Global symbols are visible in the whole file. Local ones are visible
+	// from their declaration to the byte offset End.
+	Global     bool
+	End        int
+	References []tok.Token
+}
+
+// Analysis is what editor tooling needs to know about a program: its
+// diagnostics and which use of a name refers to which declaration.
+type Analysis struct {
+	Diagnostics []*Diagnostic
+	// Symbols are in declaration order; Builtins are the native functions
+	// every program can call.
+	Symbols  []*Symbol
+	Builtins []*Symbol
+
+	// ends holds the end offsets of the scopes being resolved; globalUses
+	// are names that resolved to no local, bound by name at the end.
+	ends       []int
+	globalUses []tok.Token
+}
+
+// Analyze scans, parses and resolves source without running it. Like
+// Diagnose, it resolves whatever statements survive a syntax error.
+func Analyze(source string) *Analysis {
+	scanner := NewScanner([]rune(source))
+	parser := NewParser(scanner.ScanTokens())
+	statements := parser.Parse()
+
+	interpreter := NewInterpreter(nil)
+	resolver := NewResolver(interpreter)
+	resolver.analysis = &Analysis{}
+	resolver.Resolve(statements)
+
+	a := resolver.analysis
+	a.Diagnostics = append(append(scanner.Diagnostics(), parser.Diagnostics()...), resolver.Diagnostics()...)
+	for _, name := range interpreter.Globals.Names() {
+		value, _ := interpreter.Globals.Lookup(name)
+		arity := -1
+		if callable, ok := value.(LoxCallable); ok {
+			arity = callable.arity()
+		}
+		a.Builtins = append(a.Builtins, &Symbol{
+			Name:   tok.Token{Type: tok.IDENTIFIER, Lexeme: []rune(name)},
+			Kind:   SymbolBuiltin,
+			Arity:  arity,
+			Global: true,
+		})
+	}
+	a.bindGlobals()
+	return a
+}
+
+// bindGlobals points uses that no local scope claimed at the top-level
+// declaration with the same name, or else at the builtin.
+func (a *Analysis) bindGlobals() {
+	globals := make(map[string]*Symbol)
+	for _, symbol := range append(append([]*Symbol{}, a.Symbols...), a.Builtins...) {
+		if _, seen := globals[string(symbol.Name.Lexeme)]; symbol.Global && !seen {
+			globals[string(symbol.Name.Lexeme)] = symbol
+		}
+	}
+	for _, use := range a.globalUses {
+		if symbol, ok := globals[string(use.Lexeme)]; ok {
+			symbol.References = append(symbol.References, use)
+		}
+	}
+	for _, symbol := range a.Symbols {
+		sort.Slice(symbol.References, func(i, j int) bool {
+			return symbol.References[i].Offset < symbol.References[j].Offset
+		})
+	}
+}
+
+// SymbolAt returns the symbol declared or used at a byte offset, and nil
+// if the offset is not on a name.
+func (a *Analysis) SymbolAt(offset int) *Symbol {
+	within := func(t tok.Token) bool {
+		return offset >= t.Offset && offset <= t.Offset+t.Length
+	}
+	for _, symbol := range append(append([]*Symbol{}, a.Symbols...), a.Builtins...) {
+		if symbol.Kind != SymbolBuiltin && within(symbol.Name) {
+			return symbol
+		}
+		for _, use := range symbol.References {
+			if within(use) {
+				return symbol
+			}
+		}
+	}
+	return nil
+}
+
+// Visible returns the symbols in scope at a byte offset: every global, and
+// the locals declared before it in an enclosing scope.
+func (a *Analysis) Visible(offset int) []*Symbol {
+	visible := make([]*Symbol, 0)
+	for _, symbol := range a.Symbols {
+		switch {
+		case symbol.Kind == SymbolMethod:
+		case symbol.Global:
+			visible = append(visible, symbol)
+		case symbol.Name.Offset < offset && offset <= symbol.End:
+			visible = append(visible, symbol)
+		}
+	}
+	return visible
+}
+
+// record adds a declaration the resolver has just made to the analysis.
+func (r *Resolver) record(name tok.Token, kind SymbolKind, params []tok.Token) *Symbol {
+	if r.analysis == nil {
+		return nil
+	}
+	symbol := &Symbol{Name: name, Kind: kind, Params: params, Arity: -1}
+	switch kind {
+	case SymbolFunction, SymbolClass, SymbolMethod:
+		symbol.Arity = len(params)
+	}
+
+	if kind != SymbolMethod {
+		if len(r.scopes) == 0 {
+			symbol.Global = true
+		} else {
+			symbol.End = r.analysis.ends[len(r.analysis.ends)-1]
+			r.declarations[len(r.declarations)-1][string(name.Lexeme)].symbol = symbol
+		}
+	}
+	r.analysis.Symbols = append(r.analysis.Symbols, symbol)
+	return symbol
+}
+
+// use records a name that resolved to the local d, or to no local at all
+// when d is nil.
+func (r *Resolver) use(name tok.Token, d *declaration) {
+	if r.analysis == nil || name.Type != tok.IDENTIFIER {
+		return
+	}
+	if d == nil {
+		r.analysis.globalUses = append(r.analysis.globalUses, name)
+	} else if d.symbol != nil {
+		d.symbol.References = append(d.symbol.References, name)
+	}
+}
+
+// enter and leave bracket a scope that can hold declarations, so locals
+// know where they stop being visible.
+func (r *Resolver) enter(end int) {
+	if r.analysis != nil {
+		r.analysis.ends = append(r.analysis.ends, end)
+	}
+}
+
+func (r *Resolver) leave() {
+	if r.analysis != nil {
+		r.analysis.ends = r.analysis.ends[:len(r.analysis.ends)-1]
+	}
+}
+
+// blockEnd is where a block's scope stops. Blocks made up while
+// desugaring a for loop have no closing brace of their own.
+func blockEnd(block *st.Block) int {
+	if block.Brace.Type == tok.FOR {
+		return st.Span(block).End()
+	}
+	return block.End.Offset
+}
@@ -1,4 +1,4 @@
-package main
+package lox
 
 import (
 	"fmt"
@@ -15,19 +15,27 @@ type LoxCallable interface {
 
 // implements LoxCallabel
 type LoxFunction struct {
-	declaration stmt.Function
-	closure     environment.Environment
+	declaration   stmt.Function
+	closure       *environment.Environment
+	isInitializer bool
 }
 
-func NewLoxFunction(declaration *stmt.Function, closure environment.Environment) *LoxFunction {
+func NewLoxFunction(declaration *stmt.Function, closure *environment.Environment, isInitializer bool) *LoxFunction {
 	return &LoxFunction{
-		declaration: *declaration,
-		closure:     closure,
+		declaration:   *declaration,
+		closure:       closure,
+		isInitializer: isInitializer,
 	}
 }
 
+func (lf *LoxFunction) bind(instance *LoxInstance) *LoxFunction {
+	env := environment.NewEnvironment(lf.closure)
+	env.Define("this", instance)
+	return NewLoxFunction(&lf.declaration, env, lf.isInitializer)
+}
+
 func (lf *LoxFunction) call(interp *Interpreter, arguments []any) any {
-	env := environment.NewEnvironment(&lf.closure)
+	env := environment.NewEnvironment(lf.closure)
 
 	for i := 0; i < len(lf.declaration.Params); i++ {
 		env.Define(string(lf.declaration.Params[i].Lexeme), arguments[i])
@@ -48,13 +56,17 @@ func (lf *LoxFunction) call(interp *Interpreter, arguments []any) any {
 				if value, ok := r.(*Return); ok {
 					returnValue = value.Value
 				} else {
-					panic("Unknow error")
+					panic(r)
 				}
 			}
 		}()
 		interp.executeBlock(lf.declaration.Body, env)
 	}()
 
+	if lf.isInitializer {
+		return lf.closure.GetAt(0, thisToken)
+	}
+
 	return returnValue
 }
 
@@ -0,0 +1,119 @@
+package lox
+
+import (
+	"fmt"
+
+	err "github.com/codecrafters-io/interpreter-starter-go/app/err"
+	tok "github.com/codecrafters-io/interpreter-starter-go/app/token"
+)
+
+var thisToken = tok.NewToken(tok.THIS, []rune("this"), nil, 0)
+
+var superToken = tok.NewToken(tok.SUPER, []rune("super"), nil, 0)
+
+// implements LoxCallable
+type LoxClass struct {
+	Name       string
+	superclass *LoxClass
+	methods    map[string]*LoxFunction
+}
+
+func NewLoxClass(name string, superclass *LoxClass, methods map[string]*LoxFunction) *LoxClass {
+	return &LoxClass{
+		Name:       name,
+		superclass: superclass,
+		methods:    methods,
+	}
+}
+
+func (lc *LoxClass) findMethod(name string) *LoxFunction {
+	if method, ok := lc.methods[name]; ok {
+		return method
+	}
+	if lc.superclass != nil {
+		return lc.superclass.findMethod(name)
+	}
+	return nil
+}
+
+func (lc *LoxClass) call(interp *Interpreter, arguments []any) any {
+	instance := NewLoxInstance(lc)
+
+	if initializer := lc.findMethod("init"); initializer != nil {
+		initializer.bind(instance).call(interp, arguments)
+	}
+	return instance
+}
+
+func (lc *LoxClass) arity() int {
+	if initializer := lc.findMethod("init"); initializer != nil {
+		return initializer.arity()
+	}
+	return 0
+}
+
+func (lc *LoxClass) String() string {
+	return lc.Name
+}
+
+var _ LoxCallable = (*LoxClass)(nil)
+
+type LoxInstance struct {
+	class  *LoxClass
+	fields map[string]any
+}
+
+func NewLoxInstance(class *LoxClass) *LoxInstance {
+	return &LoxInstance{
+		class:  class,
+		fields: make(map[string]any),
+	}
+}
+
+func (li *LoxInstance) Get(name tok.Token) any {
+	if value, ok := li.fields[string(name.Lexeme)]; ok {
+		return value
+	}
+
+	if method := li.class.findMethod(string(name.Lexeme)); method != nil {
+		return method.bind(li)
+	}
+
+	panic(err.NewRuntimeError(name, fmt.Sprintf("Undefined property '%s'.", string(name.Lexeme))))
+}
+
+func (li *LoxInstance) Set(name tok.Token, value any) {
+	li.fields[string(name.Lexeme)] = value
+}
+
+func (li *LoxInstance) String() string {
+	return fmt.Sprintf("%s instance", li.class.Name)
+}
+
+// errorClass is the class of the values runtime errors are caught as.
+var errorClass = NewLoxClass("Error", nil, map[string]*LoxFunction{})
+
+// errorValue is what a catch clause binds for e: the thrown value, or an
+// Error instance with the message and line of an error the interpreter
+// raised.
+func errorValue(e *err.RuntimeError) any {
+	if value, ok := e.Thrown(); ok {
+		return value
+	}
+	instance := NewLoxInstance(errorClass)
+	instance.fields["message"] = []rune(e.Message())
+	instance.fields["line"] = float64(e.Line())
+	return instance
+}
+
+// thrownMessage describes a thrown value for when nothing catches it. An
+// instance with a message field, such as a caught error being thrown
+// again, is described by its message.
+func thrownMessage(value any) string {
+	if instance, ok := value.(*LoxInstance); ok {
+		if message, ok := instance.fields["message"]; ok {
+			return stringfy(message)
+		}
+	}
+	return stringfy(value)
+}
@@ -0,0 +1,126 @@
+package lox
+
+import (
+	"errors"
+
+	env "github.com/codecrafters-io/interpreter-starter-go/app/environment"
+	err "github.com/codecrafters-io/interpreter-starter-go/app/err"
+	st "github.com/codecrafters-io/interpreter-starter-go/app/stmt"
+)
+
+// Hook is called before the tree-walker executes a statement, which makes
+// it the place for a debugger to stop. It may block. Returning an error
+// abandons the program, and Run returns that error.
+type Hook func(i *Interpreter, stmt st.Stmt) error
+
+// Depth is the number of Lox functions currently executing.
+func (i *Interpreter) Depth() int {
+	return len(i.frames)
+}
+
+// Stack describes the functions currently executing, innermost first,
+// when the innermost one has reached line. Top-level code is left out.
+func (i *Interpreter) Stack(line int) []err.Frame {
+	return i.traceback(line)
+}
+
+// ScriptLine is the line top-level code has reached when the innermost
+// function has reached line: where the outermost call was made.
+func (i *Interpreter) ScriptLine(line int) int {
+	if len(i.frames) == 0 {
+		return line
+	}
+	return i.frames[0].line
+}
+
+// Environment is the innermost scope of the code being executed. Its
+// Enclosing chain ends at the globals.
+func (i *Interpreter) Environment() *env.Environment {
+	return i.enviroment
+}
+
+// FrameEnvironment is the innermost scope of the nth entry in the call
+// stack, counting from 0 for the code being executed. The last entry,
+// number Depth(), is top-level code.
+func (i *Interpreter) FrameEnvironment(n int) *env.Environment {
+	if n == 0 {
+		return i.enviroment
+	}
+	return i.frames[len(i.frames)-n].environment
+}
+
+// FrameFile is the absolute path of the file whose code the nth entry in
+// the call stack is running, numbered as for FrameEnvironment. It is empty
+// for a program that was not read from a file.
+func (i *Interpreter) FrameFile(n int) string {
+	return i.fileOf(i.FrameEnvironment(n))
+}
+
+// fileOf is the absolute path of the file whose code scope belongs to.
+func (i *Interpreter) fileOf(scope *env.Environment) string {
+	root := scope.Root()
+	for file, module := range i.modules {
+		if module.globals == root {
+			return file
+		}
+	}
+	if len(i.importing) == 0 {
+		return ""
+	}
+	return i.importing[0]
+}
+
+// Evaluate runs an expression in the scope of the code being executed,
+// so it can read and assign local variables. It is meant for a debugger
+// stopped in a Hook. The value is in the interpreter's own representation,
+// ready for Stringify.
+func (i *Interpreter) Evaluate(source string) (value any, e error) {
+	scanner := NewScanner([]rune(source))
+	parser := NewParser(scanner.ScanTokens())
+	expr, ok := parser.tryExpression()
+	if e := joinErrors(scanner.Errors()); e != nil {
+		return nil, e
+	}
+	if !ok {
+		return nil, errors.New("Expect a single expression.")
+	}
+
+	// Resolve against the environments that exist right now rather than
+	// the lexical scopes of some source file.
+	resolver := NewResolver(i)
+	chain := make([]*env.Environment, 0)
+	for scope := i.enviroment; scope != nil && scope.Enclosing() != nil; scope = scope.Enclosing() {
+		chain = append(chain, scope)
+	}
+	for n := len(chain) - 1; n >= 0; n-- {
+		resolver.beginScope()
+		for _, name := range chain[n].Names() {
+			resolver.scopes.Peek()[name] = true
+		}
+	}
+	resolver.resolveExpr(expr)
+	if e := joinErrors(resolver.Errors()); e != nil {
+		return nil, e
+	}
+
+	// Errors are caught here rather than by recoverError, which would
+	// forget the call stack the program is still using.
+	depth := len(i.frames)
+	defer func() {
+		if r := recover(); r != nil {
+			i.frames = i.frames[:depth]
+			switch r := r.(type) {
+			case *err.RuntimeError:
+				e = r
+			case *interrupt:
+				e = r.cause
+			default:
+				panic(r)
+			}
+		}
+	}()
+	hook := i.hook
+	i.hook = nil
+	defer func() { i.hook = hook }()
+	return i.evaluate(expr), nil
+}
@@ -0,0 +1,117 @@
+package lox
+
+import (
+	"fmt"
+
+	tok "github.com/codecrafters-io/interpreter-starter-go/app/token"
+)
+
+// Severity says how serious a diagnostic is. Only errors stop a program
+// from running.
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+	SeverityNote
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityNote:
+		return "note"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+}
+
+// Diagnostic codes. They never change meaning once published, so tools
+// can match on them instead of on the message text.
+const (
+	CodeUnexpectedCharacter = "E0001"
+	CodeUnterminatedString  = "E0002"
+	CodeInvalidNumber       = "E0003"
+
+	CodeSyntax             = "E0100"
+	CodeExpectedToken      = "E0101"
+	CodeExpectedExpression = "E0102"
+	CodeInvalidAssignment  = "E0103"
+	CodeTooManyParameters  = "E0104"
+	CodeTooManyArguments   = "E0105"
+
+	CodeRedeclared             = "E0200"
+	CodeInheritsFromItself     = "E0201"
+	CodeTopLevelReturn         = "E0202"
+	CodeInitializerReturn      = "E0203"
+	CodeThisOutsideClass       = "E0204"
+	CodeSuperOutsideClass      = "E0205"
+	CodeSuperWithoutSuperclass = "E0206"
+	CodeReadInInitializer      = "E0207"
+	CodeOutsideLoop            = "E0208"
+	CodeImportNotTopLevel      = "E0209"
+
+	CodeCompilerLimit = "E0300"
+
+	CodeUnusedVariable    = "W0400"
+	CodeShadowedParameter = "W0401"
+	CodeUnreachableCode   = "W0402"
+	CodeConstantCondition = "W0403"
+	CodeSelfAssignment    = "W0404"
+	CodeNotCallable       = "W0405"
+)
+
+// Note adds context to a diagnostic, usually by pointing at a related
+// piece of source such as an earlier declaration.
+type Note struct {
+	Message string
+	Span    tok.Span
+}
+
+// Diagnostic is a problem found while scanning, parsing or resolving a
+// program. Run and Eval return the error-severity ones joined into a single
+// error value.
+type Diagnostic struct {
+	Severity Severity
+	Code     string
+	Message  string
+	// Line and Where make up the "[line N] Error at 'x'" prefix of the
+	// classic message format.
+	Line  int
+	Where string
+	// Span is the offending source range, when it is known.
+	Span  tok.Span
+	Notes []Note
+}
+
+// SyntaxError is the error-severity Diagnostic returned from Run and Eval.
+type SyntaxError = Diagnostic
+
+func (d *Diagnostic) Error() string {
+	label := "Error"
+	switch d.Severity {
+	case SeverityWarning:
+		label = "Warning"
+	case SeverityNote:
+		label = "Note"
+	}
+	return fmt.Sprintf("[line %d] %s%s: %s", d.Line, label, d.Where, d.Message)
+}
+
+// Diagnose scans, parses and resolves source without running it and
+// returns every problem found, in source order per phase. Resolution runs
+// even when parsing failed, on whatever statements could be recovered.
+func Diagnose(source string) []*Diagnostic {
+	scanner := NewScanner([]rune(source))
+	parser := NewParser(scanner.ScanTokens())
+	statements := parser.Parse()
+
+	resolver := NewResolver(NewInterpreter(nil))
+	resolver.Resolve(statements)
+
+	diagnostics := append(scanner.Diagnostics(), parser.Diagnostics()...)
+	return append(diagnostics, resolver.Diagnostics()...)
+}
@@ -0,0 +1,108 @@
+package lox
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/codecrafters-io/interpreter-starter-go/app/compiler"
+	err "github.com/codecrafters-io/interpreter-starter-go/app/err"
+	tok "github.com/codecrafters-io/interpreter-starter-go/app/token"
+)
+
+// RuntimeError is raised while executing a program.
+type RuntimeError = err.RuntimeError
+
+// ExitCode maps an error returned by Run or Eval to the exit status the
+// CLI uses: 65 for syntax errors, 70 for runtime errors.
+func ExitCode(e error) int {
+	if e == nil {
+		return 0
+	}
+
+	var syntaxError *SyntaxError
+	if errors.As(e, &syntaxError) {
+		return 65
+	}
+	return 70
+}
+
+// reporter collects the diagnostics found by the scanner, parser and
+// resolver.
+type reporter struct {
+	diagnostics []*Diagnostic
+}
+
+func (r *reporter) report(code string, line int, where string, message string) *Diagnostic {
+	d := &Diagnostic{
+		Severity: SeverityError,
+		Code:     code,
+		Line:     line,
+		Where:    where,
+		Message:  message,
+	}
+	r.diagnostics = append(r.diagnostics, d)
+	return d
+}
+
+func (r *reporter) errorAt(token tok.Token, code string, message string) *Diagnostic {
+	var d *Diagnostic
+	if token.Type == tok.EOF {
+		d = r.report(code, token.Line, " at end", message)
+	} else {
+		d = r.report(code, token.Line, fmt.Sprintf(" at '%s'", string(token.Lexeme)), message)
+	}
+	d.Span = token.Span()
+	return d
+}
+
+func (r *reporter) warnAt(token tok.Token, code string, message string) *Diagnostic {
+	d := r.errorAt(token, code, message)
+	d.Severity = SeverityWarning
+	return d
+}
+
+func (r *reporter) HadError() bool {
+	return len(r.Errors()) > 0
+}
+
+// Errors returns the error-severity diagnostics.
+func (r *reporter) Errors() []*Diagnostic {
+	errs := make([]*Diagnostic, 0)
+	for _, d := range r.diagnostics {
+		if d.Severity == SeverityError {
+			errs = append(errs, d)
+		}
+	}
+	return errs
+}
+
+// Diagnostics returns everything reported so far, including warnings.
+func (r *reporter) Diagnostics() []*Diagnostic {
+	return r.diagnostics
+}
+
+// fromCompileError reports bytecode compiler limits as syntax errors.
+func fromCompileError(e error) error {
+	errs := make([]*Diagnostic, 0)
+	for _, e := range e.(interface{ Unwrap() []error }).Unwrap() {
+		var compileError *compiler.Error
+		if errors.As(e, &compileError) {
+			r := reporter{}
+			errs = append(errs, r.errorAt(compileError.Token, CodeCompilerLimit, compileError.Message))
+		}
+	}
+	return joinErrors(errs)
+}
+
+// joinErrors flattens syntax errors into a single error value.
+func joinErrors(errs []*Diagnostic) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	joined := make([]error, len(errs))
+	for i, e := range errs {
+		joined[i] = e
+	}
+	return errors.Join(joined...)
+}
@@ -0,0 +1,78 @@
+package lox
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/codecrafters-io/interpreter-starter-go/app/collection"
+
+	err "github.com/codecrafters-io/interpreter-starter-go/app/err"
+	tok "github.com/codecrafters-io/interpreter-starter-go/app/token"
+)
+
+// Variadic is the arity of native functions that accept any number of
+// arguments.
+const Variadic = -1
+
+// Func is a native function implemented in Go. A returned error becomes a
+// Lox runtime error at the call site.
+type Func func(args []Value) (Value, error)
+
+// implements LoxCallable
+type NativeFunction struct {
+	name   string
+	params int
+	fn     Func
+}
+
+func NewNativeFunction(name string, arity int, fn Func) *NativeFunction {
+	return &NativeFunction{
+		name:   name,
+		params: arity,
+		fn:     fn,
+	}
+}
+
+func (nf *NativeFunction) arity() int {
+	return nf.params
+}
+
+func (nf *NativeFunction) call(interp *Interpreter, arguments []any) any {
+	return nf.callAt(tok.Token{}, arguments)
+}
+
+// callAt runs the Go function, reporting its errors at the call's paren.
+func (nf *NativeFunction) callAt(paren tok.Token, arguments []any) any {
+	result, e := nf.fn(arguments)
+	if e != nil {
+		var runtimeError *RuntimeError
+		if errors.As(e, &runtimeError) {
+			panic(runtimeError)
+		}
+		panic(err.NewRuntimeError(paren, e.Error()))
+	}
+	return result
+}
+
+func (nf *NativeFunction) String() string {
+	return "<native fn>"
+}
+
+var _ LoxCallable = (*NativeFunction)(nil)
+
+func clock(args []Value) (Value, error) {
+	return float64(time.Now().Unix()), nil
+}
+
+func length(args []Value) (Value, error) {
+	switch v := args[0].(type) {
+	case *collection.List:
+		return float64(v.Len()), nil
+	case *collection.Map:
+		return float64(v.Len()), nil
+	case []rune:
+		return float64(len(v)), nil
+	}
+	return nil, fmt.Errorf("Can't take the length of a %s.", typeName(args[0]))
+}
@@ -0,0 +1,263 @@
+package lox
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/codecrafters-io/interpreter-starter-go/app/collection"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// toHost converts values of either engine to their public form. Lists
+// and maps are copied so their elements can be converted too.
+func toHost(value any) Value {
+	return convert(value, make(map[any]any), func(value any) any {
+		if runes, ok := value.([]rune); ok {
+			return string(runes)
+		}
+		return value
+	})
+}
+
+// normalize turns any Go number into a float64, the only number type Lox
+// knows about.
+func normalize(value Value) Value {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int8:
+		return float64(v)
+	case int16:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint:
+		return float64(v)
+	case uint8:
+		return float64(v)
+	case uint16:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	default:
+		return value
+	}
+}
+
+// fromHost converts a value produced by Go code into the representation
+// the tree-walking interpreter uses, where strings are []rune. Like toHost
+// it copies lists and maps.
+func fromHost(value Value) any {
+	return convert(value, make(map[any]any), func(value any) any {
+		value = normalize(value)
+		if s, ok := value.(string); ok {
+			return []rune(s)
+		}
+		return value
+	})
+}
+
+// fromHostBytecode converts a value produced by Go code into the
+// representation the bytecode VM uses, which already has strings as
+// string. Like toHost it copies lists and maps.
+func fromHostBytecode(value Value) any {
+	return convert(value, make(map[any]any), normalize)
+}
+
+// convert applies leaf to value, or to every element, key and value of a
+// list or map, copying the collections along the way. seen maps each
+// collection already copied to its copy, so shared and self-containing
+// collections keep their shape.
+func convert(value any, seen map[any]any, leaf func(any) any) any {
+	switch v := value.(type) {
+	case *collection.List:
+		if c, ok := seen[v]; ok {
+			return c
+		}
+		list := collection.NewList(make([]any, len(v.Elements)))
+		seen[v] = list
+		for n, element := range v.Elements {
+			list.Elements[n] = convert(element, seen, leaf)
+		}
+		return list
+
+	case *collection.Map:
+		if c, ok := seen[v]; ok {
+			return c
+		}
+		m := collection.NewMap()
+		seen[v] = m
+		values := v.Values()
+		for n, key := range v.Keys() {
+			m.Set(convert(key, seen, leaf), convert(values[n], seen, leaf))
+		}
+		return m
+
+	default:
+		return leaf(value)
+	}
+}
+
+func typeName(value Value) string {
+	switch value.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string, []rune:
+		return "string"
+	case *collection.List:
+		return "list"
+	case *collection.Map:
+		return "map"
+	case *LoxInstance:
+		return "instance"
+	case *LoxClass:
+		return "class"
+	case LoxCallable:
+		return "function"
+	default:
+		return "object"
+	}
+}
+
+// wrapGoFunc adapts an arbitrary Go function to Func using reflection.
+// Parameters may be numbers, strings, booleans or Value; results may be
+// nothing, a value, an error, or a value and an error.
+func wrapGoFunc(name string, fn any) (int, Func, error) {
+	fv := reflect.ValueOf(fn)
+	ft := fv.Type()
+	if ft.Kind() != reflect.Func {
+		return 0, nil, fmt.Errorf("lox: %s is a %s, not a function", name, ft)
+	}
+
+	for i := 0; i < ft.NumIn(); i++ {
+		in := ft.In(i)
+		if ft.IsVariadic() && i == ft.NumIn()-1 {
+			in = in.Elem()
+		}
+		if !convertible(in) {
+			return 0, nil, fmt.Errorf("lox: parameter %d of %s has unsupported type %s", i+1, name, in)
+		}
+	}
+
+	switch {
+	case ft.NumOut() > 2,
+		ft.NumOut() == 2 && ft.Out(1) != errorType:
+		return 0, nil, fmt.Errorf("lox: %s must return at most a value and an error", name)
+	}
+
+	arity := ft.NumIn()
+	if ft.IsVariadic() {
+		arity = Variadic
+	}
+
+	wrapped := func(args []Value) (Value, error) {
+		fixed := ft.NumIn()
+		if ft.IsVariadic() {
+			fixed--
+			if len(args) < fixed {
+				return nil, fmt.Errorf("Expected at least %d arguments but got %d.", fixed, len(args))
+			}
+		}
+
+		in := make([]reflect.Value, len(args))
+		for i, arg := range args {
+			var paramType reflect.Type
+			if i >= fixed && ft.IsVariadic() {
+				paramType = ft.In(fixed).Elem()
+			} else {
+				paramType = ft.In(i)
+			}
+
+			value, e := convertArg(arg, paramType)
+			if e != nil {
+				return nil, fmt.Errorf("Argument %d to '%s' %s", i+1, name, e.Error())
+			}
+			in[i] = value
+		}
+
+		out := fv.Call(in)
+
+		var result Value
+		for _, o := range out {
+			if o.Type() == errorType {
+				if !o.IsNil() {
+					return nil, o.Interface().(error)
+				}
+				continue
+			}
+			result = o.Interface()
+		}
+		return normalize(result), nil
+	}
+
+	return arity, wrapped, nil
+}
+
+func convertible(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.String, reflect.Bool:
+		return true
+	case reflect.Interface:
+		return t.NumMethod() == 0
+	default:
+		return false
+	}
+}
+
+var errNotInteger = errors.New("must be an integer.")
+
+func convertArg(arg Value, t reflect.Type) (reflect.Value, error) {
+	switch t.Kind() {
+	case reflect.Float32, reflect.Float64:
+		n, ok := arg.(float64)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("must be a number, not %s.", typeName(arg))
+		}
+		return reflect.ValueOf(n).Convert(t), nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := arg.(float64)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("must be a number, not %s.", typeName(arg))
+		}
+		if n != float64(int64(n)) {
+			return reflect.Value{}, errNotInteger
+		}
+		return reflect.ValueOf(int64(n)).Convert(t), nil
+
+	case reflect.String:
+		s, ok := arg.(string)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("must be a string, not %s.", typeName(arg))
+		}
+		return reflect.ValueOf(s).Convert(t), nil
+
+	case reflect.Bool:
+		b, ok := arg.(bool)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("must be a boolean, not %s.", typeName(arg))
+		}
+		return reflect.ValueOf(b).Convert(t), nil
+	}
+
+	if arg == nil {
+		return reflect.Zero(t), nil
+	}
+	return reflect.ValueOf(arg), nil
+}
@@ -0,0 +1,775 @@
+package lox
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/codecrafters-io/interpreter-starter-go/app/collection"
+	"github.com/codecrafters-io/interpreter-starter-go/app/coverage"
+	env "github.com/codecrafters-io/interpreter-starter-go/app/environment"
+	err "github.com/codecrafters-io/interpreter-starter-go/app/err"
+	exp "github.com/codecrafters-io/interpreter-starter-go/app/expr"
+	"github.com/codecrafters-io/interpreter-starter-go/app/profile"
+	st "github.com/codecrafters-io/interpreter-starter-go/app/stmt"
+	"github.com/codecrafters-io/interpreter-starter-go/app/text"
+	"github.com/codecrafters-io/interpreter-starter-go/app/token"
+	tok "github.com/codecrafters-io/interpreter-starter-go/app/token"
+	"github.com/codecrafters-io/interpreter-starter-go/app/vm"
+)
+
+type Interpreter struct {
+	Globals    env.Environment
+	enviroment *env.Environment
+	locals     map[exp.Expr]int
+	stdout     io.Writer
+	ctx        context.Context
+	steps      int
+	frames     []callFrame
+	hook       Hook
+	profiler   *profile.Profiler
+	coverage   *coverage.Coverage
+	// file is the module whose top-level code is running, which imports
+	// are relative to. importing lists the modules being loaded, the main
+	// program first, and modules caches the ones that finished.
+	file      string
+	importing []string
+	modules   map[string]*Module
+	loader    *loader
+}
+
+// callFrame records a Lox function that is currently executing, the line
+// it was called from and the caller's environment at that point.
+type callFrame struct {
+	function    string
+	line        int
+	environment *env.Environment
+}
+
+func NewInterpreter(stdout io.Writer) *Interpreter {
+	globals := *env.NewEnvironment(nil)
+
+	globals.Define("clock", NewNativeFunction("clock", 0, clock))
+	globals.Define("len", NewNativeFunction("len", 1, length))
+	globals.Define("fromCharCode", NewNativeFunction("fromCharCode", 1, text.FromCharCode))
+	i := &Interpreter{
+		Globals:    globals,
+		enviroment: &globals,
+		locals:     make(map[exp.Expr]int),
+		stdout:     stdout,
+		ctx:        context.Background(),
+		modules:    make(map[string]*Module),
+	}
+	i.loader = &loader{interpreter: i}
+	return i
+}
+
+// interrupt unwinds the interpreter when its context is cancelled.
+type interrupt struct {
+	cause error
+}
+
+// checkInterrupt polls the context every so often; doing it on every
+// statement would dominate tight loops.
+func (i *Interpreter) checkInterrupt() {
+	i.steps++
+	if i.steps%1024 != 0 {
+		return
+	}
+	if e := i.ctx.Err(); e != nil {
+		panic(&interrupt{cause: e})
+	}
+}
+
+// DefineNative makes a Go function available to scripts as a global. fn
+// sees and returns values in their public form, with strings as string;
+// lists and maps cross over as copies.
+func (i *Interpreter) DefineNative(name string, arity int, fn Func) {
+	i.Globals.Define(name, NewNativeFunction(name, arity, func(arguments []any) (any, error) {
+		args := make([]Value, len(arguments))
+		for n, argument := range arguments {
+			args[n] = toHost(argument)
+		}
+
+		result, e := fn(args)
+		return fromHost(result), e
+	}))
+}
+
+func (i *Interpreter) Resolve(expr exp.Expr, depth int) {
+	i.locals[expr] = depth
+}
+
+// recoverError turns a panic raised while interpreting into an error,
+// attaching the traceback of the functions the panic unwound through.
+func (i *Interpreter) recoverError(r any) error {
+	defer func() {
+		i.frames = i.frames[:0]
+	}()
+
+	switch r := r.(type) {
+	case *err.RuntimeError:
+		if r.Trace == nil {
+			r.Trace = i.traceback(r.Line())
+		}
+		return r
+	case *interrupt:
+		return r.cause
+	default:
+		return fmt.Errorf("Unknown error: %v", r)
+	}
+}
+
+// traceback describes the call stack, innermost first, for an error that
+// happened on line.
+func (i *Interpreter) traceback(line int) []err.Frame {
+	trace := make([]err.Frame, 0, len(i.frames))
+	for n := len(i.frames) - 1; n >= 0; n-- {
+		trace = append(trace, err.Frame{Function: i.frames[n].function, Line: line})
+		line = i.frames[n].line
+	}
+	return trace
+}
+
+func (i *Interpreter) InterpretExpression(expr exp.Expr) (value any, e error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e = i.recoverError(r)
+		}
+	}()
+
+	return i.evaluate(expr), nil
+}
+
+func (i *Interpreter) Interpret(statements []st.Stmt) (e error) {
+	_, e = i.InterpretContext(context.Background(), statements)
+	return e
+}
+
+// InterpretContext executes statements, stopping early if ctx is cancelled.
+// When the last statement is an expression statement its value is returned.
+func (i *Interpreter) InterpretContext(ctx context.Context, statements []st.Stmt) (value any, e error) {
+	i.ctx = ctx
+	defer func() {
+		i.ctx = context.Background()
+		if r := recover(); r != nil {
+			value, e = nil, i.recoverError(r)
+		}
+	}()
+
+	for n, statement := range statements {
+		if last, ok := statement.(*st.Expression); ok && n == len(statements)-1 {
+			return i.evaluate(last.Expression), nil
+		}
+		i.execute(statement)
+	}
+	return nil, nil
+}
+
+func (i *Interpreter) VisitLiteralExpr(expr *exp.Literal) interface{} {
+	return expr.Value
+}
+func (i *Interpreter) VisitLogicalExpr(expr *exp.Logical) interface{} {
+	left := i.evaluate(expr.Left)
+
+	if expr.Operator.Type == tok.OR {
+		if isTruthy(left) {
+			i.take(expr, 1)
+			return left
+		}
+	} else {
+		if !isTruthy(left) {
+			i.take(expr, 1)
+			return left
+		}
+	}
+
+	i.take(expr, 0)
+	return i.evaluate(expr.Right)
+}
+
+func (i *Interpreter) VisitGroupingExpr(expr *exp.Grouping) interface{} {
+	return i.evaluate(expr.Expression)
+}
+func (i *Interpreter) VisitBinaryExpr(expr *exp.Binary) interface{} {
+	left := i.evaluate(expr.Left)
+	right := i.evaluate(expr.Right)
+
+	switch op := expr.Operator.Type; op {
+
+	case tok.GREATER:
+		checkNumberOperands(expr.Operator, left, right)
+		return left.(float64) > right.(float64)
+	case tok.GREATER_EQUAL:
+		checkNumberOperands(expr.Operator, left, right)
+		return left.(float64) >= right.(float64)
+	case tok.LESS:
+		checkNumberOperands(expr.Operator, left, right)
+		return left.(float64) < right.(float64)
+	case tok.LESS_EQUAL:
+		checkNumberOperands(expr.Operator, left, right)
+		return left.(float64) <= right.(float64)
+	case tok.MINUS:
+		checkNumberOperands(expr.Operator, left, right)
+		return left.(float64) - right.(float64)
+
+	case tok.SLASH:
+		checkNumberOperands(expr.Operator, left, right)
+		return left.(float64) / right.(float64)
+	case tok.STAR:
+		checkNumberOperands(expr.Operator, left, right)
+		return left.(float64) * right.(float64)
+
+	case tok.EQUAL_EQUAL:
+		return isEqual(left, right)
+	case tok.BANG_EQUAL:
+		return !isEqual(left, right)
+
+	case tok.PLUS:
+		if isNumber(left) && isNumber(right) {
+
+			return left.(float64) + right.(float64)
+		}
+		if isString(left) && isString(right) {
+			return left.(string) + right.(string)
+		}
+		if isRune(left) && isRune(right) {
+			// Literals share the scanner's source buffer, so always copy
+			// instead of appending in place.
+			result := make([]rune, 0, len(left.([]rune))+len(right.([]rune)))
+			result = append(result, left.([]rune)...)
+			return append(result, right.([]rune)...)
+		}
+
+		panic(err.NewRuntimeError(expr.Operator, "Operands must be two numbers or two strings."))
+	}
+
+	return nil
+}
+
+func (i *Interpreter) VisitCallExpr(expr *exp.Call) any {
+	callee := i.evaluate(expr.Callee)
+
+	arguments := make([]any, 0)
+
+	for _, arg := range expr.Arguments {
+		arguments = append(arguments, i.evaluate(arg))
+	}
+
+	function, ok := callee.(LoxCallable)
+
+	if !ok {
+		panic(err.NewRuntimeError(expr.Paren, "Can only call functions and classes."))
+	}
+
+	if arity := function.arity(); arity != Variadic && len(arguments) != arity {
+		panic(err.NewRuntimeError(expr.Paren, fmt.Sprintf("Expected %d arguments but got %d.", arity, len(arguments))))
+	}
+
+	if i.profiler != nil {
+		name, file, line := i.profileName(function)
+		_, native := function.(*NativeFunction)
+		i.profiler.Enter(name, file, line, native)
+		defer i.profiler.Exit()
+	}
+
+	if native, ok := function.(*NativeFunction); ok {
+		return native.callAt(expr.Paren, arguments)
+	}
+
+	// Go can't recover from running out of stack, so deep recursion stops
+	// at the same depth as on the VM.
+	if len(i.frames) == vm.FramesMax {
+		panic(err.NewRuntimeError(expr.Paren, "Stack overflow."))
+	}
+	i.frames = append(i.frames, callFrame{function: frameName(function), line: expr.Paren.Line, environment: i.enviroment})
+	result := function.call(i, arguments)
+	i.frames = i.frames[:len(i.frames)-1]
+	return result
+}
+
+// frameName is how a callable shows up in tracebacks. Calling a class runs
+// its initializer.
+func frameName(function LoxCallable) string {
+	switch function := function.(type) {
+	case *LoxFunction:
+		return string(function.declaration.Name.Lexeme)
+	case *LoxClass:
+		return "init"
+	default:
+		return function.String()
+	}
+}
+
+// profileName is how a callable shows up in a profile, along with the line
+// it was declared on. Calling a class is profiled as the class.
+func (i *Interpreter) profileName(function LoxCallable) (string, string, int) {
+	switch function := function.(type) {
+	case *LoxFunction:
+		return string(function.declaration.Name.Lexeme), i.fileOf(function.closure), function.declaration.Name.Line
+	case *LoxClass:
+		if initializer := function.findMethod("init"); initializer != nil {
+			return function.Name, i.fileOf(initializer.closure), initializer.declaration.Name.Line
+		}
+		for _, method := range function.methods {
+			return function.Name, i.fileOf(method.closure), 0
+		}
+		return function.Name, i.FrameFile(0), 0
+	case *NativeFunction:
+		return function.name, "", 0
+	default:
+		return function.String(), "", 0
+	}
+}
+
+func (i *Interpreter) VisitUnaryExpr(expr *exp.Unary) any {
+	right := i.evaluate(expr.Right)
+
+	switch t := expr.Operator.Type; t {
+	case tok.MINUS:
+		checkNumberOperand(expr.Operator, right)
+		return -right.(float64)
+	case tok.BANG:
+		return !isTruthy(right)
+
+	}
+
+	return nil
+}
+
+//	func (i *Interpreter) VisitVarExpr(expr *exp.Var) interface{} {
+//		return i.enviroment.Get(expr.Name)
+//	}
+func (i *Interpreter) VisitVariableExpr(expr *exp.Variable) any {
+	return i.lookUpVariable(expr.Name, expr)
+	// return i.enviroment.Get(expr.Name)
+}
+
+func (i *Interpreter) VisitGetExpr(expr *exp.Get) any {
+	object := i.evaluate(expr.Object)
+	if instance, ok := object.(*LoxInstance); ok {
+		return instance.Get(expr.Name)
+	}
+	if module, ok := object.(*Module); ok {
+		return module.Get(expr.Name)
+	}
+
+	var method collection.Method
+	var found bool
+	switch object := object.(type) {
+	case *collection.List:
+		method, found = object.Method(string(expr.Name.Lexeme))
+	case *collection.Map:
+		method, found = object.Method(string(expr.Name.Lexeme))
+	case []rune:
+		method, found = text.Method(object, string(expr.Name.Lexeme))
+	default:
+		panic(err.NewRuntimeError(expr.Name, "Only instances have properties."))
+	}
+	if !found {
+		panic(err.NewRuntimeError(expr.Name, fmt.Sprintf("Undefined property '%s'.", string(expr.Name.Lexeme))))
+	}
+	return NewNativeFunction(method.Name, method.Arity, method.Fn)
+}
+
+func (i *Interpreter) VisitSetExpr(expr *exp.Set) any {
+	object := i.evaluate(expr.Object)
+
+	instance, ok := object.(*LoxInstance)
+	if !ok {
+		panic(err.NewRuntimeError(expr.Name, "Only instances have fields."))
+	}
+
+	value := i.evaluate(expr.Value)
+	instance.Set(expr.Name, value)
+	return value
+}
+
+func (i *Interpreter) VisitListExpr(expr *exp.List) any {
+	elements := make([]any, 0, len(expr.Elements))
+	for _, element := range expr.Elements {
+		elements = append(elements, i.evaluate(element))
+	}
+	return collection.NewList(elements)
+}
+
+func (i *Interpreter) VisitIndexExpr(expr *exp.Index) any {
+	object := i.evaluate(expr.Object)
+	index := i.evaluate(expr.Index)
+
+	var value any
+	var e error
+	switch object := object.(type) {
+	case *collection.List:
+		value, e = object.Get(index)
+	case *collection.Map:
+		value, e = object.Get(index)
+	case []rune:
+		value, e = text.Index(object, index)
+	default:
+		panic(err.NewRuntimeError(expr.Bracket, "Only lists, maps and strings can be indexed."))
+	}
+	if e != nil {
+		panic(err.NewRuntimeError(expr.Bracket, e.Error()))
+	}
+	return value
+}
+
+func (i *Interpreter) VisitIndexSetExpr(expr *exp.IndexSet) any {
+	object := i.evaluate(expr.Object)
+	index := i.evaluate(expr.Index)
+	value := i.evaluate(expr.Value)
+
+	switch object := object.(type) {
+	case *collection.List:
+		if e := object.Set(index, value); e != nil {
+			panic(err.NewRuntimeError(expr.Bracket, e.Error()))
+		}
+	case *collection.Map:
+		object.Set(index, value)
+	case []rune:
+		panic(err.NewRuntimeError(expr.Bracket, "Strings can't be modified."))
+	default:
+		panic(err.NewRuntimeError(expr.Bracket, "Only lists and maps can be indexed."))
+	}
+	return value
+}
+
+func (i *Interpreter) VisitMapExpr(expr *exp.Map) any {
+	m := collection.NewMap()
+	for n, key := range expr.Keys {
+		k := i.evaluate(key)
+		m.Set(k, i.evaluate(expr.Values[n]))
+	}
+	return m
+}
+
+func (i *Interpreter) VisitFunctionExpr(expr *exp.Function) any {
+	return NewLoxFunction(expr.Declaration.(*st.Function), i.enviroment, false)
+}
+
+func (i *Interpreter) VisitThisExpr(expr *exp.This) any {
+	return i.lookUpVariable(expr.Keyword, expr)
+}
+
+func (i *Interpreter) VisitSuperExpr(expr *exp.Super) any {
+	distance := i.locals[expr]
+	superclass := i.enviroment.GetAt(distance, superToken).(*LoxClass)
+
+	// "this" is always one level nearer than "super"'s environment.
+	object := i.enviroment.GetAt(distance-1, thisToken).(*LoxInstance)
+
+	method := superclass.findMethod(string(expr.Method.Lexeme))
+	if method == nil {
+		panic(err.NewRuntimeError(expr.Method, fmt.Sprintf("Undefined property '%s'.", string(expr.Method.Lexeme))))
+	}
+	return method.bind(object)
+}
+
+func (i *Interpreter) lookUpVariable(name token.Token, expr exp.Expr) any {
+	if distance, ok := i.locals[expr]; ok {
+		return i.enviroment.GetAt(distance, name)
+	}
+	// Each module has its own globals at the root of its environments.
+	return i.enviroment.Root().Get(name)
+}
+
+func (i *Interpreter) evaluate(expr exp.Expr) any {
+	return expr.Accept(i)
+}
+
+func (i *Interpreter) execute(stmt st.Stmt) any {
+	i.checkInterrupt()
+	if i.hook != nil {
+		if e := i.hook(i, stmt); e != nil {
+			panic(&interrupt{cause: e})
+		}
+	}
+	if i.profiler != nil || i.coverage != nil {
+		if _, ok := stmt.(*st.Block); !ok {
+			line := st.Span(stmt).Line
+			if i.profiler != nil {
+				i.profiler.Line(i.FrameFile(0), line)
+			}
+			if i.coverage != nil {
+				i.coverage.Run(stmt)
+			}
+		}
+	}
+	// fmt.Println("execute stmt ----------- ", stmt)
+	// i.enviroment.Print()
+
+	return stmt.Accept(i)
+}
+func (i *Interpreter) executeBlock(statements []st.Stmt, environment *env.Environment) {
+	previous := i.enviroment
+	i.enviroment = environment
+	defer func() {
+		// if r := recover(); r != nil {
+		// 	// recover from panic
+		// 	if value, ok := r.(*Return); ok {
+		// 		panic(value)
+		// 	} else {
+		// 		panic("Unknow error")
+		// 	}
+		// 	// fmt.Println("recover from panic executeBlock")
+		// 	// fmt.Println(r)
+		// }
+		i.enviroment = previous
+	}()
+	for _, statement := range statements {
+		i.execute(statement)
+	}
+}
+
+func (i *Interpreter) VisitBlockStmt(stmt *st.Block) any {
+	i.executeBlock(stmt.Statements, env.NewEnvironment(i.enviroment))
+	return nil
+}
+
+func checkNumberOperand(operator tok.Token, operand any) {
+	if !isNumber(operand) {
+		panic(err.NewRuntimeError(operator, "Operand must be a number."))
+	}
+}
+
+func checkNumberOperands(operator tok.Token, left, right any) {
+	if !isNumber(left) || !isNumber(right) {
+		panic(err.NewRuntimeError(operator, "Operands must be numbers."))
+	}
+}
+
+// Stmt Visitor
+func (i *Interpreter) VisitExpressionStmt(stmt *st.Expression) any {
+	i.evaluate(stmt.Expression)
+	return nil
+}
+
+func (i *Interpreter) VisitClassStmt(stmt *st.Class) any {
+	var superclass *LoxClass = nil
+	if stmt.Superclass != nil {
+		class, ok := i.evaluate(stmt.Superclass).(*LoxClass)
+		if !ok {
+			panic(err.NewRuntimeError(stmt.Superclass.Name, "Superclass must be a class."))
+		}
+		superclass = class
+	}
+
+	i.enviroment.Define(string(stmt.Name.Lexeme), nil)
+
+	if superclass != nil {
+		i.enviroment = env.NewEnvironment(i.enviroment)
+		i.enviroment.Define("super", superclass)
+	}
+
+	methods := make(map[string]*LoxFunction)
+	for _, method := range stmt.Methods {
+		isInitializer := string(method.Name.Lexeme) == "init"
+		methods[string(method.Name.Lexeme)] = NewLoxFunction(method, i.enviroment, isInitializer)
+	}
+
+	class := NewLoxClass(string(stmt.Name.Lexeme), superclass, methods)
+
+	if superclass != nil {
+		i.enviroment = i.enviroment.Enclosing()
+	}
+
+	i.enviroment.Assign(stmt.Name, class)
+	return nil
+}
+
+func (i *Interpreter) VisitFunctionStmt(stmt *st.Function) any {
+	function := NewLoxFunction(stmt, i.enviroment, false)
+	i.enviroment.Define(string(stmt.Name.Lexeme), function)
+	return nil
+}
+
+func (i *Interpreter) VisitIfStmt(stmt *st.If) any {
+	if isTruthy(i.evaluate(stmt.Condition)) {
+		i.take(stmt, 0)
+		i.execute(stmt.ThenBranch)
+	} else {
+		i.take(stmt, 1)
+		if stmt.ElseBranch != nil {
+			i.execute(stmt.ElseBranch)
+		}
+	}
+	return nil
+}
+
+// take records a branch for coverage. node is an *st.If, *st.While or
+// *exp.Logical.
+func (i *Interpreter) take(node any, arm int) {
+	if i.coverage != nil {
+		i.coverage.Take(node, arm)
+	}
+}
+
+func (i *Interpreter) VisitPrintStmt(stmt *st.Print) any {
+	value := i.evaluate(stmt.Expression)
+	fmt.Fprintln(i.stdout, stringfy(value))
+	return nil
+}
+
+func (i *Interpreter) VisitReturnStmt(stmt *st.Return) any {
+	var value any = nil
+	if stmt.Value != nil {
+		value = i.evaluate(stmt.Value)
+	}
+	panic(&Return{Value: value})
+}
+
+func (i *Interpreter) VisitThrowStmt(stmt *st.Throw) any {
+	value := i.evaluate(stmt.Value)
+	panic(err.NewThrownError(stmt.Keyword, thrownMessage(value), value))
+}
+
+func (i *Interpreter) VisitTryStmt(stmt *st.Try) any {
+	if stmt.Finally != nil {
+		defer i.finally(stmt.Finally, len(i.frames))
+	}
+
+	if stmt.Catch == nil {
+		i.execute(stmt.Body)
+		return nil
+	}
+
+	if caught := i.attempt(stmt.Body); caught != nil {
+		environment := env.NewEnvironment(i.enviroment)
+		environment.Define(string(stmt.CatchName.Lexeme), errorValue(caught))
+		i.executeBlock(stmt.Catch.Statements, environment)
+	}
+	return nil
+}
+
+// attempt executes body and returns the runtime error it raised, if any,
+// with the call stack unwound back to where body started. Returns and
+// cancellation pass through.
+func (i *Interpreter) attempt(body st.Stmt) (caught *err.RuntimeError) {
+	depth := len(i.frames)
+	defer func() {
+		if r := recover(); r != nil {
+			runtimeError, ok := r.(*err.RuntimeError)
+			if !ok {
+				panic(r)
+			}
+			i.frames = i.frames[:depth]
+			caught = runtimeError
+		}
+	}()
+
+	i.execute(body)
+	return nil
+}
+
+// finally runs a finally block however its try statement is left, then
+// carries on unwinding. A traceback is taken first, since running the
+// block unwinds the call stack.
+func (i *Interpreter) finally(block *st.Block, depth int) {
+	r := recover()
+	if _, ok := r.(*interrupt); ok {
+		panic(r)
+	}
+	if runtimeError, ok := r.(*err.RuntimeError); ok && runtimeError.Trace == nil {
+		runtimeError.Trace = i.traceback(runtimeError.Line())
+	}
+	i.frames = i.frames[:depth]
+
+	i.execute(block)
+	if r != nil {
+		panic(r)
+	}
+}
+
+func (i *Interpreter) VisitVarStmt(stmt *st.Var) any {
+	var value any = nil
+
+	if stmt.Initializer != nil {
+		value = i.evaluate(stmt.Initializer)
+	}
+	i.enviroment.Define(string(stmt.Name.Lexeme), value)
+	return nil
+}
+
+func (i *Interpreter) VisitWhileStmt(stmt *st.While) any {
+	for isTruthy(i.evaluate(stmt.Condition)) {
+		i.take(stmt, 0)
+		if broke := i.loopBody(stmt.Body); broke {
+			break
+		}
+		if stmt.Increment != nil {
+			i.evaluate(stmt.Increment)
+		}
+	}
+	i.take(stmt, 1)
+
+	return nil
+}
+
+// loopBody executes one pass through a loop body and reports whether it
+// ended with a break. A continue just ends the pass early.
+func (i *Interpreter) loopBody(body st.Stmt) (broke bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			switch r.(type) {
+			case *Break:
+				broke = true
+			case *Continue:
+			default:
+				panic(r)
+			}
+		}
+	}()
+
+	i.execute(body)
+	return false
+}
+
+func (i *Interpreter) VisitBreakStmt(stmt *st.Break) any {
+	panic(&Break{})
+}
+
+func (i *Interpreter) VisitContinueStmt(stmt *st.Continue) any {
+	panic(&Continue{})
+}
+func (i *Interpreter) VisitAssignExpr(expr *exp.Assign) any {
+	value := i.evaluate(expr.Value)
+
+	// i.enviroment.Assign(expr.Name, value)
+	distance, exists := i.locals[expr]
+	if exists {
+		i.enviroment.AssignAt(distance, expr.Name, value)
+	} else {
+		i.enviroment.Root().Assign(expr.Name, value)
+	}
+	return value
+}
+
+// func (i *Interpreter) VisitUnaryStmt(stmt *st.UnaryStmt) interface{} {
+// 	return nil
+// }
+
+// func (i *Interpreter) VisitVariableStmt(stmt *st.Variable) interface{} {
+// 	return nil
+// }
+
+// func (i *Interpreter) VisitBlockStmt(stmt *Block) interface{} {
+// 	return nil
+// }
+// func (i *Interpreter) VisitIfStmt(stmt *If) interface{} {
+// 	return nil
+// }
+
+// func (i *Interpreter) VisitFunctionStmt(stmt *Function) interface{} {
+// 	return nil
+// }
+
+// func (i *Interpreter) VisitWhileStmt(stmt *While) interface{} {
+// 	return nil
+// }
+// func (i *Interpreter) VisitReturnStmt(stmt *Return) interface{} {
+// 	return nil
+// }
+
+var _ exp.ExprVisitor = (*Interpreter)(nil)
+var _ st.StmtVisitor = (*Interpreter)(nil)
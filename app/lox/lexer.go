@@ -1,18 +1,31 @@
-package main
+package lox
 
 import (
 	"fmt"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	tok "github.com/codecrafters-io/interpreter-starter-go/app/token"
 )
 
 type Scanner struct {
+	reporter
 	source  []rune
 	tokens  []tok.Token
 	start   int
 	current int
 	line    int
+	// lineStart is the index of the first character on the current line.
+	lineStart int
+	// offset is the byte offset of current; startOffset and startColumn
+	// describe where the lexeme being scanned began.
+	offset      int
+	startOffset int
+	startColumn int
+	// comments holds the // comments seen so far, which the parser never
+	// sees but the formatter puts back.
+	comments []tok.Token
 }
 
 func NewScanner(source []rune) *Scanner {
@@ -33,6 +46,8 @@ func (s *Scanner) ScanTokens() []tok.Token {
 	for !s.isAtEnd() {
 		// We are at the beginning of the next lexeme.
 		s.start = s.current
+		s.startOffset = s.offset
+		s.startColumn = s.current - s.lineStart + 1
 		s.scanToken()
 	}
 
@@ -41,6 +56,8 @@ func (s *Scanner) ScanTokens() []tok.Token {
 		Lexeme:  []rune(""),
 		Literal: nil,
 		Line:    s.line,
+		Column:  s.current - s.lineStart + 1,
+		Offset:  s.offset,
 	})
 
 	return s.tokens
@@ -49,6 +66,7 @@ func (s *Scanner) ScanTokens() []tok.Token {
 func (s *Scanner) advance() rune {
 	r := s.source[s.current]
 	s.current++
+	s.offset += utf8.RuneLen(r)
 	return r
 }
 
@@ -57,6 +75,7 @@ func (s *Scanner) match(expected rune) bool {
 		return false
 	}
 	s.current++
+	s.offset += utf8.RuneLen(expected)
 	return true
 }
 
@@ -78,13 +97,14 @@ func (s *Scanner) string() {
 	for s.peek() != '"' && !s.isAtEnd() {
 		if s.peek() == '\n' {
 			s.line++
+			s.lineStart = s.current + 1
 		}
 		s.advance()
 	}
 
 	if s.isAtEnd() {
 		// error(s.line, "Unterminated string.")
-		report(s.line, "", "Unterminated string.")
+		s.report(CodeUnterminatedString, s.line, "", "Unterminated string.").Span = s.span()
 		return
 	}
 
@@ -110,7 +130,7 @@ func (s *Scanner) number() {
 	val, err := strconv.ParseFloat(numStr, 64)
 	if err != nil {
 		// error(s.line, "Invalid number format.")
-		report(s.line, "", "Invalid number format.")
+		s.report(CodeInvalidNumber, s.line, "", "Invalid number format.").Span = s.span()
 		return
 	}
 	s.addToken(tok.NUMBER, val)
@@ -141,8 +161,14 @@ func (s *Scanner) scanToken() {
 		s.addToken(tok.LEFT_BRACE, nil)
 	case '}':
 		s.addToken(tok.RIGHT_BRACE, nil)
+	case '[':
+		s.addToken(tok.LEFT_BRACKET, nil)
+	case ']':
+		s.addToken(tok.RIGHT_BRACKET, nil)
 	case ',':
 		s.addToken(tok.COMMA, nil)
+	case ':':
+		s.addToken(tok.COLON, nil)
 	case '.':
 		s.addToken(tok.DOT, nil)
 	case '-':
@@ -162,6 +188,8 @@ func (s *Scanner) scanToken() {
 	case '=':
 		if s.match('=') {
 			s.addToken(tok.EQUAL_EQUAL, nil)
+		} else if s.match('>') {
+			s.addToken(tok.ARROW, nil)
 		} else {
 			s.addToken(tok.EQUAL, nil)
 		}
@@ -182,6 +210,14 @@ func (s *Scanner) scanToken() {
 			for s.peek() != '\n' && !s.isAtEnd() {
 				s.advance()
 			}
+			s.comments = append(s.comments, tok.Token{
+				Type:   tok.COMMENT,
+				Lexeme: []rune(strings.TrimRight(string(s.source[s.start:s.current]), " \t\r")),
+				Line:   s.line,
+				Column: s.startColumn,
+				Offset: s.startOffset,
+				Length: s.offset - s.startOffset,
+			})
 
 		} else {
 			s.addToken(tok.SLASH, nil)
@@ -191,6 +227,7 @@ func (s *Scanner) scanToken() {
 		break
 	case '\n':
 		s.line++
+		s.lineStart = s.current
 	case '"':
 		s.string()
 
@@ -201,11 +238,32 @@ func (s *Scanner) scanToken() {
 			s.identifier()
 		} else {
 			// error(s.line, fmt.Sprintf("Unexpected character: %c", c))
-			report(s.line, "", fmt.Sprintf("Unexpected character: %c", c))
+			s.report(CodeUnexpectedCharacter, s.line, "", fmt.Sprintf("Unexpected character: %c", c)).Span = s.span()
 		}
 	}
 }
 
+// Comments returns the comments found by ScanTokens in source order.
+func (s *Scanner) Comments() []tok.Token {
+	return s.comments
+}
+
+// span covers the lexeme scanned so far.
+func (s *Scanner) span() tok.Span {
+	line := s.line
+	for _, r := range s.source[s.start:s.current] {
+		if r == '\n' {
+			line--
+		}
+	}
+	return tok.Span{
+		Line:   line,
+		Column: s.startColumn,
+		Offset: s.startOffset,
+		Length: s.offset - s.startOffset,
+	}
+}
+
 func (s *Scanner) addToken(t tok.TokenType, literal any) {
 	text := s.source[s.start:s.current]
 	s.tokens = append(s.tokens, tok.Token{
@@ -213,5 +271,8 @@ func (s *Scanner) addToken(t tok.TokenType, literal any) {
 		Lexeme:  text,
 		Literal: literal,
 		Line:    s.line,
+		Column:  s.startColumn,
+		Offset:  s.startOffset,
+		Length:  s.offset - s.startOffset,
 	})
 }
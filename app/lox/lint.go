@@ -0,0 +1,261 @@
+package lox
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	exp "github.com/codecrafters-io/interpreter-starter-go/app/expr"
+	st "github.com/codecrafters-io/interpreter-starter-go/app/stmt"
+	tok "github.com/codecrafters-io/interpreter-starter-go/app/token"
+)
+
+// LintRule describes one of the checks run by Lint. Rules can be turned
+// off by code or by name.
+type LintRule struct {
+	Code        string
+	Name        string
+	Description string
+}
+
+var LintRules = []LintRule{
+	{CodeUnusedVariable, "unused-variable", "a local variable is declared but never read"},
+	{CodeShadowedParameter, "shadowed-parameter", "a parameter has the same name as an outer variable"},
+	{CodeUnreachableCode, "unreachable-code", "a statement follows a return in the same block"},
+	{CodeConstantCondition, "constant-condition", "an if or while condition is a literal"},
+	{CodeSelfAssignment, "self-assignment", "a variable or field is assigned to itself"},
+	{CodeNotCallable, "not-callable", "a literal is called like a function"},
+}
+
+// RuleName returns the name of the lint rule with the given code, or ""
+// if there is none.
+func RuleName(code string) string {
+	for _, rule := range LintRules {
+		if rule.Code == code {
+			return rule.Name
+		}
+	}
+	return ""
+}
+
+// LintConfig is read from a JSON file such as
+//
+//	{"disable": ["unused-variable", "W0403"]}
+type LintConfig struct {
+	Disable []string `json:"disable"`
+}
+
+func ReadLintConfig(path string) (LintConfig, error) {
+	var config LintConfig
+	data, e := os.ReadFile(path)
+	if e != nil {
+		return config, e
+	}
+	if e := json.Unmarshal(data, &config); e != nil {
+		return config, fmt.Errorf("%s: %w", path, e)
+	}
+	return config, nil
+}
+
+// ignoreDirective starts a comment that silences warnings, e.g.
+// "// lox:ignore unused-variable". After code it covers its own line; on a
+// line of its own it covers the next one. Without any rules listed, every
+// warning is silenced.
+const ignoreDirective = "// lox:ignore"
+
+// Lint checks source for likely mistakes without running it. Syntax errors
+// are returned alone; otherwise the result holds the resolver's errors and
+// the warnings that are neither disabled by config nor ignored in a
+// comment.
+func Lint(source string, config LintConfig) []*Diagnostic {
+	scanner := NewScanner([]rune(source))
+	tokens := scanner.ScanTokens()
+	parser := NewParser(tokens)
+	statements := parser.Parse()
+	if scanner.HadError() || parser.HadError() {
+		return append(scanner.Errors(), parser.Errors()...)
+	}
+
+	resolver := NewResolver(NewInterpreter(nil))
+	resolver.lint = true
+	resolver.globals = make(map[string]bool)
+	resolver.Resolve(statements)
+
+	disabled := make(map[string]bool)
+	for _, rule := range config.Disable {
+		disabled[rule] = true
+	}
+	ignored := ignoredLines(scanner.Comments(), tokens)
+
+	diagnostics := make([]*Diagnostic, 0)
+	for _, d := range resolver.Diagnostics() {
+		if d.Severity == SeverityWarning {
+			if disabled[d.Code] || disabled[RuleName(d.Code)] {
+				continue
+			}
+			if rules, ok := ignored[d.Line]; ok && (len(rules) == 0 || rules[d.Code] || rules[RuleName(d.Code)]) {
+				continue
+			}
+		}
+		diagnostics = append(diagnostics, d)
+	}
+	// Unused variables are only known once their scope ends, so put
+	// everything back in line order.
+	sort.SliceStable(diagnostics, func(i, j int) bool { return diagnostics[i].Line < diagnostics[j].Line })
+	return diagnostics
+}
+
+// ignoredLines maps each line covered by an ignore comment to the rules
+// it silences. An empty set means all of them. tokens tell comments that
+// follow code from those on a line of their own.
+func ignoredLines(comments []tok.Token, tokens []tok.Token) map[int]map[string]bool {
+	code := make(map[int]bool)
+	for _, token := range tokens {
+		if token.Type != tok.EOF {
+			code[token.Line] = true
+		}
+	}
+
+	lines := make(map[int]map[string]bool)
+	for _, comment := range comments {
+		text, ok := strings.CutPrefix(string(comment.Lexeme), ignoreDirective)
+		if !ok || (text != "" && text[0] != ' ' && text[0] != '\t') {
+			continue
+		}
+		rules := make(map[string]bool)
+		for _, rule := range strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+			rules[rule] = true
+		}
+		line := comment.Line
+		if !code[line] {
+			line++
+		}
+		// A line can be covered by both a directive above it and one
+		// trailing it, in which case it ignores the rules of both.
+		if covered, ok := lines[line]; !ok {
+			lines[line] = rules
+		} else if len(covered) > 0 && len(rules) > 0 {
+			for rule := range rules {
+				covered[rule] = true
+			}
+		} else {
+			lines[line] = map[string]bool{}
+		}
+	}
+	return lines
+}
+
+func (r *Resolver) checkUnused(scope map[string]*declaration) {
+	unused := make([]*declaration, 0)
+	for _, d := range scope {
+		if !d.read && !d.param {
+			unused = append(unused, d)
+		}
+	}
+	sort.Slice(unused, func(i, j int) bool { return unused[i].name.Offset < unused[j].name.Offset })
+
+	for _, d := range unused {
+		r.warnAt(d.name, CodeUnusedVariable, fmt.Sprintf("Local variable '%s' is never read.", string(d.name.Lexeme)))
+	}
+}
+
+func (r *Resolver) checkShadowing(param tok.Token) {
+	name := string(param.Lexeme)
+	shadowed := r.globals[name]
+	// The innermost scope is the function's own.
+	for _, scope := range r.scopes[:len(r.scopes)-1] {
+		if _, ok := scope[name]; ok {
+			shadowed = true
+		}
+	}
+	if shadowed {
+		r.warnAt(param, CodeShadowedParameter, fmt.Sprintf("Parameter '%s' shadows a variable in an enclosing scope.", name))
+	}
+}
+
+func (r *Resolver) checkUnreachable(statements []st.Stmt) {
+	for n, statement := range statements[:max(len(statements)-1, 0)] {
+		var keyword tok.Token
+		switch statement := statement.(type) {
+		case *st.Return:
+			keyword = statement.Keyword
+		case *st.Throw:
+			keyword = statement.Keyword
+		case *st.Break:
+			keyword = statement.Keyword
+		case *st.Continue:
+			keyword = statement.Keyword
+		}
+		if keyword.Lexeme != nil {
+			span := st.Span(statements[n+1])
+			d := r.report(CodeUnreachableCode, span.Line, "", fmt.Sprintf("Unreachable code after '%s'.", string(keyword.Lexeme)))
+			d.Severity = SeverityWarning
+			d.Span = span
+			return
+		}
+	}
+}
+
+func (r *Resolver) checkCondition(condition exp.Expr) {
+	literal, ok := unwrap(condition).(*exp.Literal)
+	// A for loop without a condition gets a made-up true literal.
+	if !ok || literal.Token.Type == tok.FOR {
+		return
+	}
+	r.warnAt(literal.Token, CodeConstantCondition, fmt.Sprintf("Condition is always %t.", isTruthy(literal.Value)))
+}
+
+func (r *Resolver) checkSelfAssignment(expr exp.Expr) {
+	switch expr := expr.(type) {
+	case *exp.Assign:
+		if value, ok := unwrap(expr.Value).(*exp.Variable); ok && string(value.Name.Lexeme) == string(expr.Name.Lexeme) {
+			r.warnAt(expr.Name, CodeSelfAssignment, fmt.Sprintf("'%s' is assigned to itself.", string(expr.Name.Lexeme)))
+		}
+	case *exp.Set:
+		value, ok := unwrap(expr.Value).(*exp.Get)
+		if ok && string(value.Name.Lexeme) == string(expr.Name.Lexeme) && sameObject(expr.Object, value.Object) {
+			r.warnAt(expr.Name, CodeSelfAssignment, fmt.Sprintf("'%s' is assigned to itself.", string(expr.Name.Lexeme)))
+		}
+	}
+}
+
+// sameObject reports whether two expressions plainly name the same
+// object: the same variable, or both 'this'.
+func sameObject(a exp.Expr, b exp.Expr) bool {
+	switch a := unwrap(a).(type) {
+	case *exp.Variable:
+		b, ok := unwrap(b).(*exp.Variable)
+		return ok && string(a.Name.Lexeme) == string(b.Name.Lexeme)
+	case *exp.This:
+		_, ok := unwrap(b).(*exp.This)
+		return ok
+	}
+	return false
+}
+
+func (r *Resolver) checkCallee(expr *exp.Call) {
+	var start tok.Token
+	switch callee := unwrap(expr.Callee).(type) {
+	case *exp.Literal:
+		start = callee.Token
+	case *exp.List:
+		start = callee.Start
+	case *exp.Map:
+		start = callee.Start
+	default:
+		return
+	}
+	r.warnAt(start, CodeNotCallable, "Can only call functions and classes, not a literal.")
+}
+
+func unwrap(expr exp.Expr) exp.Expr {
+	for {
+		grouping, ok := expr.(*exp.Grouping)
+		if !ok {
+			return expr
+		}
+		expr = grouping.Expression
+	}
+}
@@ -0,0 +1,241 @@
+// Package lox embeds the Lox interpreter in Go programs.
+//
+//	machine := lox.NewVM(lox.Options{Stdout: &buf})
+//	value, err := machine.Eval(ctx, "1 + 2")
+package lox
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/codecrafters-io/interpreter-starter-go/app/compiler"
+	"github.com/codecrafters-io/interpreter-starter-go/app/coverage"
+	"github.com/codecrafters-io/interpreter-starter-go/app/profile"
+	st "github.com/codecrafters-io/interpreter-starter-go/app/stmt"
+	"github.com/codecrafters-io/interpreter-starter-go/app/vm"
+)
+
+// Value is a Lox value as seen from Go: nil, bool, float64, string, or one
+// of the interpreter's callable and instance types.
+type Value = any
+
+type Engine int
+
+const (
+	// EngineTreeWalker evaluates the AST directly.
+	EngineTreeWalker Engine = iota
+	// EngineBytecode compiles to bytecode and runs it on the stack VM.
+	EngineBytecode
+)
+
+type Options struct {
+	// Stdout receives the output of print statements. Defaults to os.Stdout.
+	Stdout io.Writer
+	// Stderr is where ReportError writes. Defaults to os.Stderr.
+	Stderr io.Writer
+	Engine Engine
+	// ShowSource makes ReportError quote the offending source line under
+	// each error, with the problem underlined.
+	ShowSource bool
+	// Hook, when set, is called before the tree-walker executes each
+	// statement. The bytecode engine ignores it.
+	Hook Hook
+	// Profiler, when set, is told about every call and statement the
+	// tree-walker executes. The bytecode engine ignores it.
+	Profiler *profile.Profiler
+	// Coverage, when set, records the lines and branches the tree-walker
+	// executes. The bytecode engine ignores it.
+	Coverage *coverage.Coverage
+	// File is where the program was read from. Imports are looked up
+	// relative to its directory, or to the working directory if it is
+	// empty, and then in each directory of SearchPath.
+	File       string
+	SearchPath []string
+}
+
+// VM is an embeddable Lox interpreter. Globals defined by one call to Run
+// or Eval stay visible to the next. A VM is not safe for concurrent use.
+type VM struct {
+	opts        Options
+	interpreter *Interpreter
+	machine     *vm.VM
+	// source is the program most recently passed to Run or Eval.
+	source string
+}
+
+func NewVM(opts Options) *VM {
+	if opts.Stdout == nil {
+		opts.Stdout = os.Stdout
+	}
+	if opts.Stderr == nil {
+		opts.Stderr = os.Stderr
+	}
+
+	v := &VM{
+		opts:        opts,
+		interpreter: NewInterpreter(opts.Stdout),
+	}
+	v.interpreter.hook = opts.Hook
+	v.interpreter.profiler = opts.Profiler
+	v.interpreter.coverage = opts.Coverage
+	v.interpreter.loader.searchPath = opts.SearchPath
+	file := ""
+	if opts.File != "" {
+		file, _ = filepath.Abs(opts.File)
+		v.interpreter.file = file
+		v.interpreter.importing = []string{file}
+	}
+	if opts.Engine == EngineBytecode {
+		v.machine = vm.New(opts.Stdout)
+		v.machine.SetLoader(v.interpreter.loader, file)
+	}
+	return v
+}
+
+// DefineFunc registers a native function as a global. Both engines pass
+// fn its arguments and take its result in their public form, with lists
+// and maps copied. arity may be Variadic, in which case fn validates the
+// argument count itself.
+func (v *VM) DefineFunc(name string, arity int, fn Func) {
+	v.interpreter.DefineNative(name, arity, fn)
+	if v.machine != nil {
+		// arguments is a slice of the VM's stack, so fn gets a copy it may
+		// keep.
+		v.machine.DefineNative(name, arity, func(arguments []any) (any, error) {
+			args := make([]Value, len(arguments))
+			for n, argument := range arguments {
+				args[n] = toHost(argument)
+			}
+
+			result, e := fn(args)
+			return fromHostBytecode(result), e
+		})
+	}
+}
+
+// DefineGoFunc registers an ordinary Go function such as
+// func(float64, string) (bool, error) as a global, converting arguments
+// and results with reflection. Arguments of the wrong type are reported
+// as runtime errors at the call site.
+func (v *VM) DefineGoFunc(name string, fn any) error {
+	arity, wrapped, e := wrapGoFunc(name, fn)
+	if e != nil {
+		return e
+	}
+	v.DefineFunc(name, arity, wrapped)
+	return nil
+}
+
+// Run executes source as a program. Syntax errors are returned as one or
+// more joined *SyntaxError values, runtime failures as *RuntimeError, and
+// cancellation as ctx.Err().
+func (v *VM) Run(ctx context.Context, source string) error {
+	_, e := v.exec(ctx, source, false)
+	return e
+}
+
+// Eval executes source like Run and returns the value of its final
+// expression statement. A lone expression needs no trailing semicolon.
+func (v *VM) Eval(ctx context.Context, source string) (Value, error) {
+	return v.exec(ctx, source, true)
+}
+
+// Globals returns the current global variables, including natives.
+func (v *VM) Globals() map[string]Value {
+	globals := make(map[string]Value)
+	if v.machine != nil {
+		for name, value := range v.machine.Globals() {
+			globals[name] = toHost(value)
+		}
+		return globals
+	}
+
+	for _, name := range v.interpreter.Globals.Names() {
+		value, _ := v.interpreter.Globals.Lookup(name)
+		globals[name] = toHost(value)
+	}
+	return globals
+}
+
+func (v *VM) exec(ctx context.Context, source string, eval bool) (Value, error) {
+	v.source = source
+	statements, e := v.compile(source, eval)
+	if e != nil {
+		return nil, e
+	}
+	return v.execute(ctx, statements, eval)
+}
+
+// execute runs statements that compile produced.
+func (v *VM) execute(ctx context.Context, statements []st.Stmt, eval bool) (Value, error) {
+	if v.machine != nil {
+		compile := compiler.Compile
+		if eval {
+			compile = compiler.CompileEval
+		}
+		function, e := compile(statements)
+		if e != nil {
+			return nil, fromCompileError(e)
+		}
+		value, e := v.machine.Interpret(ctx, function)
+		return toHost(value), e
+	}
+
+	if v.opts.Coverage != nil {
+		v.opts.Coverage.Instrument(statements)
+	}
+	value, e := v.interpreter.InterpretContext(ctx, statements)
+	return toHost(value), e
+}
+
+// compile scans, parses and resolves source.
+func (v *VM) compile(source string, eval bool) ([]st.Stmt, error) {
+	scanner := NewScanner([]rune(source))
+	tokens := scanner.ScanTokens()
+
+	parser := NewParser(tokens)
+	if eval && !scanner.HadError() {
+		if expr, ok := parser.tryExpression(); ok {
+			statements := []st.Stmt{&st.Expression{Expression: expr}}
+			return statements, v.resolve(statements)
+		}
+	}
+
+	statements := parser.Parse()
+	if e := joinErrors(append(scanner.Errors(), parser.Errors()...)); e != nil {
+		return nil, e
+	}
+	return statements, v.resolve(statements)
+}
+
+func (v *VM) resolve(statements []st.Stmt) error {
+	resolver := NewResolver(v.interpreter)
+	resolver.Resolve(statements)
+	return joinErrors(resolver.Errors())
+}
+
+// ReportError writes e to Options.Stderr the way the command line tool
+// does and returns the matching exit code.
+func (v *VM) ReportError(e error) int {
+	if e == nil {
+		return 0
+	}
+
+	if v.opts.ShowSource {
+		io.WriteString(v.opts.Stderr, Render(v.source, e))
+		return ExitCode(e)
+	}
+
+	if joined, ok := e.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			io.WriteString(v.opts.Stderr, e.Error()+"\n")
+		}
+	} else if ExitCode(e) == 65 {
+		io.WriteString(v.opts.Stderr, e.Error()+"\n")
+	} else {
+		io.WriteString(v.opts.Stderr, e.Error())
+	}
+	return ExitCode(e)
+}
@@ -0,0 +1,311 @@
+package lox_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/codecrafters-io/interpreter-starter-go/app/collection"
+	"github.com/codecrafters-io/interpreter-starter-go/app/lox"
+)
+
+// The embedding API has to behave the same whichever engine runs the
+// program, so every test runs on both.
+var engines = []struct {
+	name   string
+	engine lox.Engine
+}{
+	{"tree-walker", lox.EngineTreeWalker},
+	{"bytecode", lox.EngineBytecode},
+}
+
+func forEachEngine(t *testing.T, test func(t *testing.T, engine lox.Engine)) {
+	for _, e := range engines {
+		t.Run(e.name, func(t *testing.T) {
+			t.Parallel()
+			test(t, e.engine)
+		})
+	}
+}
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		source string
+		want   lox.Value
+	}{
+		{"1 + 2", 3.0},
+		{`"lo" + "x"`, "lox"},
+		{"!nil", true},
+		{"nil", nil},
+		{"var a = 4; a * a;", 16.0},
+		{"var a = 4;", nil},
+		{`fun greet(name) { return "hi " + name; } greet("go");`, "hi go"},
+	}
+
+	forEachEngine(t, func(t *testing.T, engine lox.Engine) {
+		for _, test := range tests {
+			machine := lox.NewVM(lox.Options{Engine: engine})
+			got, e := machine.Eval(context.Background(), test.source)
+			if e != nil {
+				t.Errorf("Eval(%q): %v", test.source, e)
+				continue
+			}
+			if got != test.want {
+				t.Errorf("Eval(%q) = %#v, want %#v", test.source, got, test.want)
+			}
+		}
+	})
+}
+
+func TestRunKeepsGlobals(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, engine lox.Engine) {
+		var out bytes.Buffer
+		machine := lox.NewVM(lox.Options{Engine: engine, Stdout: &out})
+		if e := machine.Run(context.Background(), `var count = 1; print "ran";`); e != nil {
+			t.Fatal(e)
+		}
+		if out.String() != "ran\n" {
+			t.Errorf("stdout %q, want %q", out.String(), "ran\n")
+		}
+
+		got, e := machine.Eval(context.Background(), "count + 1")
+		if e != nil || got != 2.0 {
+			t.Errorf("Eval after Run = %#v, %v, want 2", got, e)
+		}
+		if globals := machine.Globals(); globals["count"] != 1.0 {
+			t.Errorf("Globals()[count] = %#v, want 1", globals["count"])
+		}
+	})
+}
+
+func TestErrors(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, engine lox.Engine) {
+		machine := lox.NewVM(lox.Options{Engine: engine})
+
+		e := machine.Run(context.Background(), "print ;")
+		var syntaxError *lox.SyntaxError
+		if !errors.As(e, &syntaxError) {
+			t.Fatalf("syntax error %v (%T) is not a *SyntaxError", e, e)
+		}
+		if syntaxError.Line != 1 || syntaxError.Message != "Expect expression." {
+			t.Errorf("syntax error %q", syntaxError.Error())
+		}
+		if code := lox.ExitCode(e); code != 65 {
+			t.Errorf("ExitCode(syntax error) = %d, want 65", code)
+		}
+
+		e = machine.Run(context.Background(), "\n-nil;")
+		var runtimeError *lox.RuntimeError
+		if !errors.As(e, &runtimeError) {
+			t.Fatalf("runtime error %v (%T) is not a *RuntimeError", e, e)
+		}
+		if runtimeError.Line() != 2 || runtimeError.Message() != "Operand must be a number." {
+			t.Errorf("runtime error %q", runtimeError.Error())
+		}
+		if code := lox.ExitCode(e); code != 70 {
+			t.Errorf("ExitCode(runtime error) = %d, want 70", code)
+		}
+
+		if code := lox.ExitCode(nil); code != 0 {
+			t.Errorf("ExitCode(nil) = %d, want 0", code)
+		}
+	})
+}
+
+func TestCancel(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, engine lox.Engine) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		machine := lox.NewVM(lox.Options{Engine: engine})
+		e := machine.Run(ctx, "while (true) {}")
+		if !errors.Is(e, context.Canceled) {
+			t.Errorf("Run with a cancelled context = %v, want context.Canceled", e)
+		}
+	})
+}
+
+func TestDefineGoFunc(t *testing.T) {
+	tests := []struct {
+		source string
+		// Either the value or the runtime error is expected.
+		want    lox.Value
+		message string
+	}{
+		{`repeat("ab", 3)`, "ababab", ""},
+		{`repeat(3, 3)`, nil, "Argument 1 to 'repeat' must be a string, not number."},
+		{`repeat("ab", 1.5)`, nil, "Argument 2 to 'repeat' must be an integer."},
+		{`repeat("ab", -1)`, nil, "count must not be negative"},
+		{`repeat("ab")`, nil, "Expected 2 arguments but got 1."},
+	}
+
+	forEachEngine(t, func(t *testing.T, engine lox.Engine) {
+		machine := lox.NewVM(lox.Options{Engine: engine})
+		e := machine.DefineGoFunc("repeat", func(s string, count int) (string, error) {
+			if count < 0 {
+				return "", errors.New("count must not be negative")
+			}
+			return string(bytes.Repeat([]byte(s), count)), nil
+		})
+		if e != nil {
+			t.Fatal(e)
+		}
+
+		for _, test := range tests {
+			got, e := machine.Eval(context.Background(), test.source)
+			if test.message == "" {
+				if e != nil || got != test.want {
+					t.Errorf("Eval(%q) = %#v, %v, want %#v", test.source, got, e, test.want)
+				}
+				continue
+			}
+			var runtimeError *lox.RuntimeError
+			if !errors.As(e, &runtimeError) || runtimeError.Message() != test.message {
+				t.Errorf("Eval(%q) error %v, want runtime error %q", test.source, e, test.message)
+			}
+		}
+
+		if e := machine.DefineGoFunc("bad", 3); e == nil {
+			t.Error("DefineGoFunc accepted a value that is not a function")
+		}
+		if e := machine.DefineGoFunc("bad", func(ch chan int) {}); e == nil {
+			t.Error("DefineGoFunc accepted an unsupported parameter type")
+		}
+	})
+}
+
+func TestDefineFuncArguments(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, engine lox.Engine) {
+		machine := lox.NewVM(lox.Options{Engine: engine})
+		var kept []lox.Value
+		machine.DefineFunc("keep", lox.Variadic, func(args []lox.Value) (lox.Value, error) {
+			kept = args
+			return int32(len(args)), nil
+		})
+
+		got, e := machine.Eval(context.Background(), `keep("a", 2) + (1 + (2 + (3 + 4)));`)
+		if e != nil || got != 12.0 {
+			t.Errorf("Eval = %#v, %v, want 12", got, e)
+		}
+		if len(kept) != 2 || kept[0] != "a" || kept[1] != 2.0 {
+			t.Errorf("arguments kept by the native changed to %#v", kept)
+		}
+	})
+}
+
+func TestListsCrossAsCopies(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, engine lox.Engine) {
+		machine := lox.NewVM(lox.Options{Engine: engine})
+		machine.DefineFunc("make", 0, func(args []lox.Value) (lox.Value, error) {
+			return collection.NewList([]any{"go", int64(2)}), nil
+		})
+		machine.DefineFunc("grow", 1, func(args []lox.Value) (lox.Value, error) {
+			list := args[0].(*collection.List)
+			list.Elements = append(list.Elements, "more")
+			return list, nil
+		})
+
+		source := `
+			var made = make();
+			var xs = ["a"];
+			var ys = grow(xs);
+			[made[0] == "go", made[0] + "!", made[1] + 1, len(xs), len(ys), xs == ys];`
+		got, e := machine.Eval(context.Background(), source)
+		if e != nil {
+			t.Fatal(e)
+		}
+		want := []any{true, "go!", 3.0, 1.0, 2.0, false}
+		if list, ok := got.(*collection.List); !ok || !reflect.DeepEqual(list.Elements, want) {
+			t.Errorf("Eval = %#v, want %#v", got, want)
+		}
+
+		got, e = machine.Eval(context.Background(), `var cycle = [1, "b"]; cycle.push(cycle); cycle;`)
+		list, ok := got.(*collection.List)
+		if e != nil || !ok || len(list.Elements) != 3 {
+			t.Fatalf("Eval = %#v, %v, want a list of 3", got, e)
+		}
+		if _, ok := list.Elements[1].(string); !ok {
+			t.Errorf("list element %#v is not a string", list.Elements[1])
+		}
+		if list.Elements[2] != list {
+			t.Error("a list containing itself lost its shape")
+		}
+	})
+}
+
+func TestMapsCrossAsCopies(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, engine lox.Engine) {
+		machine := lox.NewVM(lox.Options{Engine: engine})
+		machine.DefineFunc("make", 0, func(args []lox.Value) (lox.Value, error) {
+			m := collection.NewMap()
+			m.Set("name", "go")
+			m.Set(int8(1), collection.NewList([]any{"x"}))
+			return m, nil
+		})
+		machine.DefineFunc("tag", 1, func(args []lox.Value) (lox.Value, error) {
+			m := args[0].(*collection.Map)
+			m.Set("tagged", true)
+			return m, nil
+		})
+
+		source := `
+			var made = make();
+			var m = {"k": "v"};
+			var tagged = tag(m);
+			[made["name"] + "!", made[1][0] == "x", m.has("tagged"), tagged["k"] == "v", m == tagged];`
+		got, e := machine.Eval(context.Background(), source)
+		if e != nil {
+			t.Fatal(e)
+		}
+		want := []any{"go!", true, false, true, false}
+		if list, ok := got.(*collection.List); !ok || !reflect.DeepEqual(list.Elements, want) {
+			t.Errorf("Eval = %#v, want %#v", got, want)
+		}
+
+		got, e = machine.Eval(context.Background(), `{"a": ["b"]}`)
+		m, ok := got.(*collection.Map)
+		if e != nil || !ok || !m.Has("a") {
+			t.Fatalf("Eval = %#v, %v, want a map with key a", got, e)
+		}
+		value, _ := m.Get("a")
+		if element := value.(*collection.List).Elements[0]; element != "b" {
+			t.Errorf("map value element %#v, want \"b\"", element)
+		}
+	})
+}
+
+func TestLintIgnore(t *testing.T) {
+	source := `
+fun f() {
+  // lox:ignore unused-variable
+  var a = 1; // lox:ignore self-assignment
+  var b = 2; b = b; // lox:ignore unused-variable
+  var c = 3;
+  // lox:ignore
+  var d = 4;
+}
+`
+	var got []string
+	for _, d := range lox.Lint(source, lox.LintConfig{}) {
+		got = append(got, fmt.Sprintf("%d %s", d.Line, lox.RuleName(d.Code)))
+	}
+	// The directive above a is merged with the one trailing it, the one
+	// trailing b covers only its own line, and the one above d covers d.
+	want := []string{"5 self-assignment", "6 unused-variable"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("warnings %v, want %v", got, want)
+	}
+}
+
+func TestAssertEqualMessage(t *testing.T) {
+	source := `fun testLists() { assertEqual([1, 2], [1, 2]); }`
+	e := lox.RunTest(context.Background(), source, "testLists", lox.Options{})
+	want := "Expected [1, 2] but got [1, 2] (different lists with equal contents)."
+	var assertion *lox.AssertionError
+	if !errors.As(e, &assertion) || !strings.Contains(e.Error(), want) {
+		t.Errorf("RunTest = %v, want an assertion failure %q", e, want)
+	}
+}
@@ -0,0 +1,221 @@
+package lox
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/codecrafters-io/interpreter-starter-go/app/compiler"
+	env "github.com/codecrafters-io/interpreter-starter-go/app/environment"
+	err "github.com/codecrafters-io/interpreter-starter-go/app/err"
+	st "github.com/codecrafters-io/interpreter-starter-go/app/stmt"
+	tok "github.com/codecrafters-io/interpreter-starter-go/app/token"
+)
+
+// Module is the namespace an import binds. Its exports are the top-level
+// names the module file defines, except those starting with an underscore.
+type Module struct {
+	Name string
+	file string
+	// globals is the module's own global environment. It starts out with
+	// the natives, which builtins remembers so they are not exported.
+	globals  *env.Environment
+	builtins map[string]any
+}
+
+func newModule(file string, natives *env.Environment) *Module {
+	m := &Module{
+		Name:     moduleName(file),
+		file:     file,
+		globals:  env.NewEnvironment(nil),
+		builtins: make(map[string]any),
+	}
+	for _, name := range natives.Names() {
+		value, _ := natives.Lookup(name)
+		if native, ok := value.(*NativeFunction); ok {
+			m.globals.Define(name, native)
+			m.builtins[name] = native
+		}
+	}
+	return m
+}
+
+// Export returns the value of an exported name.
+func (m *Module) Export(name string) (any, bool) {
+	if strings.HasPrefix(name, "_") {
+		return nil, false
+	}
+	value, ok := m.globals.Lookup(name)
+	if builtin, isBuiltin := m.builtins[name]; !ok || (isBuiltin && value == builtin) {
+		return nil, false
+	}
+	return value, true
+}
+
+// Get returns an export, panicking with a runtime error at name if the
+// module has no such export.
+func (m *Module) Get(name tok.Token) any {
+	value, ok := m.Export(string(name.Lexeme))
+	if !ok {
+		panic(err.NewRuntimeError(name, fmt.Sprintf("Module '%s' has no export '%s'.", m.Name, string(name.Lexeme))))
+	}
+	return value
+}
+
+func (m *Module) String() string {
+	return fmt.Sprintf("<module %s>", m.Name)
+}
+
+// moduleName is the file name without its directory and extension.
+func moduleName(file string) string {
+	base := filepath.Base(file)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// importedNames are the names an import statement binds.
+func importedNames(stmt *st.Import) []tok.Token {
+	if len(stmt.Names) > 0 {
+		return stmt.Names
+	}
+	return []tok.Token{stmt.Alias}
+}
+
+// importPath is the path an import statement names, as written.
+func importPath(stmt *st.Import) string {
+	return string(stmt.Path.Literal.([]rune))
+}
+
+// importCycle describes a cycle of imports for an error message. files
+// are the modules being loaded, outermost first, ending with the one that
+// would be loaded again.
+func importCycle(files []string) string {
+	names := make([]string, len(files))
+	for n, file := range files {
+		names[n] = filepath.Base(file)
+	}
+	return fmt.Sprintf("Import cycle: %s.", strings.Join(names, " -> "))
+}
+
+// loader finds, parses and resolves the files that scripts import. Both
+// engines share it, but each runs its own copy of every module it loads.
+type loader struct {
+	interpreter *Interpreter
+	searchPath  []string
+}
+
+// Find returns the file that importing path from the module in file
+// refers to. A relative path is looked up next to the importing file,
+// then in each directory of the search path.
+func (l *loader) Find(path string, from string) (string, error) {
+	candidates := []string{path}
+	if !filepath.IsAbs(path) {
+		candidates = []string{filepath.Join(filepath.Dir(from), path)}
+		for _, dir := range l.searchPath {
+			candidates = append(candidates, filepath.Join(dir, path))
+		}
+	}
+
+	for _, candidate := range candidates {
+		if info, e := os.Stat(candidate); e == nil && !info.IsDir() {
+			return filepath.Abs(candidate)
+		}
+	}
+	return "", fmt.Errorf("Can't find module '%s'.", path)
+}
+
+// Parse reads, parses and resolves a module file.
+func (l *loader) Parse(file string) ([]st.Stmt, error) {
+	source, e := os.ReadFile(file)
+	if e != nil {
+		return nil, fmt.Errorf("Can't read module '%s'.", filepath.Base(file))
+	}
+
+	scanner := NewScanner([]rune(string(source)))
+	parser := NewParser(scanner.ScanTokens())
+	statements := parser.Parse()
+	diagnostics := append(scanner.Errors(), parser.Errors()...)
+	if len(diagnostics) == 0 {
+		resolver := NewResolver(l.interpreter)
+		resolver.Resolve(statements)
+		diagnostics = resolver.Errors()
+	}
+	if len(diagnostics) > 0 {
+		lines := make([]string, len(diagnostics))
+		for n, diagnostic := range diagnostics {
+			lines[n] = diagnostic.Error()
+		}
+		return nil, fmt.Errorf("Module '%s' has errors:\n%s", filepath.Base(file), strings.Join(lines, "\n"))
+	}
+	return statements, nil
+}
+
+// Compile parses a module file and compiles it for the bytecode VM.
+func (l *loader) Compile(file string) (*compiler.Function, error) {
+	statements, e := l.Parse(file)
+	if e != nil {
+		return nil, e
+	}
+	function, e := compiler.CompileModule(statements)
+	if e != nil {
+		return nil, errors.Join(fmt.Errorf("Module '%s' has errors:", filepath.Base(file)), e)
+	}
+	function.Name = fmt.Sprintf("<module %s>", moduleName(file))
+	return function, nil
+}
+
+// VisitImportStmt binds the module, or the names imported from it.
+func (i *Interpreter) VisitImportStmt(stmt *st.Import) any {
+	module := i.importModule(stmt)
+	if len(stmt.Names) == 0 {
+		i.enviroment.Define(string(stmt.Alias.Lexeme), module)
+		return nil
+	}
+	for _, name := range stmt.Names {
+		i.enviroment.Define(string(name.Lexeme), module.Get(name))
+	}
+	return nil
+}
+
+// importModule returns the module an import statement names, running it
+// first unless an earlier import already did. A module that fails part
+// way through is forgotten, so importing it again runs it again.
+func (i *Interpreter) importModule(stmt *st.Import) *Module {
+	file, e := i.loader.Find(importPath(stmt), i.file)
+	if e != nil {
+		panic(err.NewRuntimeError(stmt.Path, e.Error()))
+	}
+	for n, loading := range i.importing {
+		if loading == file {
+			panic(err.NewRuntimeError(stmt.Path, importCycle(append(i.importing[n:], file))))
+		}
+	}
+	if module, ok := i.modules[file]; ok {
+		return module
+	}
+
+	statements, e := i.loader.Parse(file)
+	if e != nil {
+		panic(err.NewRuntimeError(stmt.Path, e.Error()))
+	}
+
+	module := newModule(file, &i.Globals)
+	i.modules[file] = module
+	importer := i.file
+	i.file = file
+	i.importing = append(i.importing, file)
+	i.frames = append(i.frames, callFrame{function: fmt.Sprintf("<module %s>", module.Name), line: stmt.Path.Line, environment: i.enviroment})
+	defer func() {
+		i.file = importer
+		i.importing = i.importing[:len(i.importing)-1]
+		if r := recover(); r != nil {
+			delete(i.modules, file)
+			panic(r)
+		}
+		i.frames = i.frames[:len(i.frames)-1]
+	}()
+
+	i.executeBlock(statements, module.globals)
+	return module
+}
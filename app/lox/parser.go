@@ -0,0 +1,907 @@
+package lox
+
+import (
+	"fmt"
+
+	exp "github.com/codecrafters-io/interpreter-starter-go/app/expr"
+	st "github.com/codecrafters-io/interpreter-starter-go/app/stmt"
+	tok "github.com/codecrafters-io/interpreter-starter-go/app/token"
+)
+
+type Parser struct {
+	reporter
+	tokens  []tok.Token
+	current int
+}
+
+func NewParser(tokens []tok.Token) *Parser {
+	return &Parser{
+		tokens:  tokens,
+		current: 0,
+	}
+}
+func (p *Parser) ParseExpression() exp.Expr {
+	return p.expression()
+}
+
+func (p *Parser) Parse() []st.Stmt {
+	// create an arraylist of statements
+	statements := make([]st.Stmt, 0)
+
+	for !p.isAtEnd() {
+		// statements = append(statements, p.statement())
+		// Declarations that failed to parse come back nil.
+		if statement := p.declaration(); statement != nil {
+			statements = append(statements, statement)
+		}
+	}
+	// return p.expression()
+	return statements
+}
+
+// tryExpression parses the tokens as a single expression. If they are
+// anything else the parser is left as it was.
+func (p *Parser) tryExpression() (expr exp.Expr, ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			ok = false
+		}
+		if !ok {
+			p.current = 0
+			p.diagnostics = nil
+		}
+	}()
+
+	expr = p.expression()
+	return expr, p.isAtEnd() && !p.HadError()
+}
+
+func (p *Parser) isAtEnd() bool {
+	return p.peek().Type == tok.EOF
+}
+
+func (p *Parser) expression() exp.Expr {
+	// return p.equality()
+	return p.assignment()
+}
+
+func (p *Parser) declaration() st.Stmt {
+	defer func() {
+		if r := recover(); r != nil {
+			p.synchronize()
+		}
+	}()
+
+	if p.match(tok.CLASS) {
+		return p.classDeclaration()
+	}
+
+	// "fun (" starts an anonymous function in an expression statement.
+	if p.check(tok.FUN) && p.tokens[p.current+1].Type != tok.LEFT_PAREN {
+		p.advance()
+		return p.function("function")
+	}
+
+	if p.match(tok.VAR) {
+		return p.varDeclaration()
+	}
+
+	if p.match(tok.IMPORT) {
+		return p.importDeclaration()
+	}
+
+	// A name followed by a string can only start a from import.
+	if p.checkWord("from") && p.tokens[p.current+1].Type == tok.STRING {
+		p.advance()
+		return p.fromDeclaration()
+	}
+
+	return p.statement()
+}
+
+func (p *Parser) importDeclaration() st.Stmt {
+	keyword := p.previous()
+	path := p.consume(tok.STRING, "Expect module path after 'import'.")
+	if !p.checkWord("as") {
+		panic(p.errorAt(p.peek(), CodeExpectedToken, "Expect 'as' after module path."))
+	}
+	p.advance()
+	alias := p.consume(tok.IDENTIFIER, "Expect module name after 'as'.")
+	p.consume(tok.SEMICOLON, "Expect ';' after import.")
+
+	return &st.Import{
+		Keyword: keyword,
+		Path:    path,
+		Alias:   alias,
+	}
+}
+
+func (p *Parser) fromDeclaration() st.Stmt {
+	keyword := p.previous()
+	path := p.consume(tok.STRING, "Expect module path after 'from'.")
+	p.consume(tok.IMPORT, "Expect 'import' after module path.")
+
+	names := []tok.Token{p.consume(tok.IDENTIFIER, "Expect name to import.")}
+	for p.match(tok.COMMA) {
+		names = append(names, p.consume(tok.IDENTIFIER, "Expect name to import."))
+	}
+	p.consume(tok.SEMICOLON, "Expect ';' after import.")
+
+	return &st.Import{
+		Keyword: keyword,
+		Path:    path,
+		Names:   names,
+	}
+}
+
+func (p *Parser) classDeclaration() st.Stmt {
+	name := p.consume(tok.IDENTIFIER, "Expect class name.")
+
+	var superclass *exp.Variable = nil
+	if p.match(tok.LESS) {
+		p.consume(tok.IDENTIFIER, "Expect superclass name.")
+		superclass = &exp.Variable{
+			Name: p.previous(),
+		}
+	}
+
+	p.consume(tok.LEFT_BRACE, "Expect '{' before class body.")
+
+	methods := make([]*st.Function, 0)
+	for !p.check(tok.RIGHT_BRACE) && !p.isAtEnd() {
+		methods = append(methods, p.function("method"))
+	}
+
+	end := p.consume(tok.RIGHT_BRACE, "Expect '}' after class body.")
+
+	return &st.Class{
+		Name:       name,
+		Superclass: superclass,
+		Methods:    methods,
+		End:        end,
+	}
+}
+
+func (p *Parser) varDeclaration() st.Stmt {
+	keyword := p.previous()
+	name := p.consume(tok.IDENTIFIER, "Expect variable name.")
+
+	var initializer exp.Expr = nil
+	if p.match(tok.EQUAL) {
+		initializer = p.expression()
+	}
+	p.consume(tok.SEMICOLON, "Expect ';' after variable declaration.")
+
+	return &st.Var{
+		Keyword:     keyword,
+		Name:        name,
+		Initializer: initializer,
+	}
+}
+func (p *Parser) statement() st.Stmt {
+
+	if p.match(tok.BREAK) {
+		return p.breakStatement()
+	}
+
+	if p.match(tok.CONTINUE) {
+		return p.continueStatement()
+	}
+
+	if p.match(tok.FOR) {
+		return p.forStatement()
+	}
+
+	if p.match(tok.IF) {
+		return p.ifStatement()
+	}
+
+	if p.match(tok.PRINT) {
+		return p.printStatement()
+	}
+
+	if p.match(tok.RETURN) {
+		return p.returnStatement()
+	}
+
+	if p.match(tok.THROW) {
+		return p.throwStatement()
+	}
+
+	if p.match(tok.TRY) {
+		return p.tryStatement()
+	}
+
+	if p.match(tok.WHILE) {
+		return p.whileStatement()
+	}
+
+	if p.match(tok.LEFT_BRACE) {
+		return p.blockStatement()
+	}
+
+	return p.expressionStatement()
+}
+
+func (p *Parser) breakStatement() st.Stmt {
+	keyword := p.previous()
+	p.consume(tok.SEMICOLON, "Expect ';' after 'break'.")
+	return &st.Break{Keyword: keyword}
+}
+
+func (p *Parser) continueStatement() st.Stmt {
+	keyword := p.previous()
+	p.consume(tok.SEMICOLON, "Expect ';' after 'continue'.")
+	return &st.Continue{Keyword: keyword}
+}
+
+func (p *Parser) forStatement() st.Stmt {
+	keyword := p.previous()
+	p.consume(tok.LEFT_PAREN, "Expect '(' after 'for'.")
+
+	var initializer st.Stmt = nil
+	if p.match(tok.SEMICOLON) {
+		initializer = nil
+	} else if p.match(tok.VAR) {
+		initializer = p.varDeclaration()
+	} else {
+		initializer = p.expressionStatement()
+	}
+
+	var condition exp.Expr = nil
+	if !p.check(tok.SEMICOLON) {
+		condition = p.expression()
+	}
+	p.consume(tok.SEMICOLON, "Expect ';' after loop condition.")
+
+	var increment exp.Expr = nil
+	if !p.check(tok.RIGHT_PAREN) {
+		increment = p.expression()
+	}
+
+	p.consume(tok.RIGHT_PAREN, "Expect ')' after for clauses.")
+
+	if condition == nil {
+		condition = &exp.Literal{
+			Token: keyword,
+			Value: true,
+		}
+	}
+
+	var body st.Stmt = &st.While{
+		Keyword:   keyword,
+		Condition: condition,
+		Body:      p.statement(),
+		Increment: increment,
+	}
+
+	if initializer != nil {
+		body = &st.Block{
+			Brace: keyword,
+			Statements: []st.Stmt{
+				initializer, body,
+			},
+		}
+	}
+
+	return body
+}
+
+func (p *Parser) ifStatement() st.Stmt {
+	keyword := p.previous()
+	p.consume(tok.LEFT_PAREN, "Expect '(' after 'if'.")
+	condition := p.expression()
+	p.consume(tok.RIGHT_PAREN, "Expect ')' after if condition.")
+
+	thenBranch := p.statement()
+	var elseBranch st.Stmt = nil
+	if p.match(tok.ELSE) {
+		elseBranch = p.statement()
+	}
+
+	return &st.If{
+		Keyword:    keyword,
+		Condition:  condition,
+		ThenBranch: thenBranch,
+		ElseBranch: elseBranch,
+	}
+}
+
+func (p *Parser) printStatement() st.Stmt {
+	keyword := p.previous()
+	value := p.expression()
+	p.consume(tok.SEMICOLON, "Expect ';' after value.")
+	return &st.Print{
+		Keyword:    keyword,
+		Expression: value,
+	}
+}
+
+func (p *Parser) returnStatement() st.Stmt {
+	keyword := p.previous()
+	var value exp.Expr = nil
+
+	if !p.check(tok.SEMICOLON) {
+		value = p.expression()
+	}
+	p.consume(tok.SEMICOLON, "Expect ';' after return value.")
+
+	return &st.Return{
+		Keyword: keyword,
+		Value:   value,
+	}
+}
+
+func (p *Parser) throwStatement() st.Stmt {
+	keyword := p.previous()
+	value := p.expression()
+	p.consume(tok.SEMICOLON, "Expect ';' after thrown value.")
+
+	return &st.Throw{
+		Keyword: keyword,
+		Value:   value,
+	}
+}
+
+func (p *Parser) tryStatement() st.Stmt {
+	statement := &st.Try{Keyword: p.previous()}
+	p.consume(tok.LEFT_BRACE, "Expect '{' after 'try'.")
+	statement.Body = p.blockStatement()
+
+	if p.match(tok.CATCH) {
+		p.consume(tok.LEFT_PAREN, "Expect '(' after 'catch'.")
+		statement.CatchName = p.consume(tok.IDENTIFIER, "Expect error variable name.")
+		p.consume(tok.RIGHT_PAREN, "Expect ')' after error variable.")
+		p.consume(tok.LEFT_BRACE, "Expect '{' before catch body.")
+		statement.Catch = p.blockStatement()
+	}
+
+	if p.match(tok.FINALLY) {
+		p.consume(tok.LEFT_BRACE, "Expect '{' after 'finally'.")
+		statement.Finally = p.blockStatement()
+	}
+
+	if statement.Catch == nil && statement.Finally == nil {
+		panic(p.errorAt(p.peek(), CodeExpectedToken, "Expect 'catch' or 'finally' after try block."))
+	}
+	return statement
+}
+
+// blockStatement parses the rest of a block whose opening brace has been
+// consumed.
+func (p *Parser) blockStatement() *st.Block {
+	brace := p.previous()
+	statements := p.block()
+	return &st.Block{
+		Brace:      brace,
+		Statements: statements,
+		End:        p.previous(),
+	}
+}
+
+func (p *Parser) whileStatement() st.Stmt {
+	keyword := p.previous()
+	p.consume(tok.LEFT_PAREN, "Expect '(' after 'while'.")
+	condition := p.expression()
+	p.consume(tok.RIGHT_PAREN, "Expect ')' after condition.")
+
+	body := p.statement()
+
+	return &st.While{
+		Keyword:   keyword,
+		Condition: condition,
+		Body:      body,
+	}
+}
+
+func (p *Parser) expressionStatement() st.Stmt {
+	expr := p.expression()
+	p.consume(tok.SEMICOLON, "Expect ';' after expression.")
+	return &st.Expression{
+		Expression: expr,
+	}
+}
+
+func (p *Parser) function(kind string) *st.Function {
+	name := p.consume(tok.IDENTIFIER, fmt.Sprintf("Expected %s name.", kind))
+
+	p.consume(tok.LEFT_PAREN, fmt.Sprintf("Expect '(' after %s name.", kind))
+	parameters := p.parameters()
+
+	p.consume(tok.LEFT_BRACE, fmt.Sprintf("Expect '{' before %s body.", kind))
+
+	body := p.block()
+
+	return &st.Function{
+		Name:   name,
+		Params: parameters,
+		Body:   body,
+		End:    p.previous(),
+	}
+}
+
+// parameters parses a parameter list up to and including the closing
+// parenthesis.
+func (p *Parser) parameters() []tok.Token {
+	parameters := make([]tok.Token, 0)
+	if !p.check(tok.RIGHT_PAREN) {
+		for {
+			if len(parameters) >= 255 {
+				p.errorAt(p.peek(), CodeTooManyParameters, "Can't have more than 255 parameters.")
+			}
+			parameters = append(parameters, p.consume(tok.IDENTIFIER, "Expect parameter name."))
+			if !p.match(tok.COMMA) {
+				break
+			}
+		}
+	}
+
+	p.consume(tok.RIGHT_PAREN, "Expect ')' after parameters.")
+	return parameters
+}
+
+// anonymousFunction parses the rest of "fun (a) { ... }" once the fun
+// keyword has been consumed.
+func (p *Parser) anonymousFunction() exp.Expr {
+	keyword := p.previous()
+	p.consume(tok.LEFT_PAREN, "Expect '(' after 'fun'.")
+	parameters := p.parameters()
+	p.consume(tok.LEFT_BRACE, "Expect '{' before function body.")
+	body := p.block()
+	return p.functionExpr(keyword, parameters, body)
+}
+
+// arrowAhead reports whether the parenthesis at the current token opens
+// the parameter list of an arrow function rather than a grouping.
+func (p *Parser) arrowAhead() bool {
+	n := p.current + 1
+	if p.tokens[n].Type != tok.RIGHT_PAREN {
+		for {
+			if p.tokens[n].Type != tok.IDENTIFIER {
+				return false
+			}
+			n++
+			if p.tokens[n].Type != tok.COMMA {
+				break
+			}
+			n++
+		}
+	}
+	return p.tokens[n].Type == tok.RIGHT_PAREN && p.tokens[n+1].Type == tok.ARROW
+}
+
+// arrowFunction parses "(a) => a * 2" or "(a) => { ... }" once the opening
+// parenthesis has been consumed.
+func (p *Parser) arrowFunction() exp.Expr {
+	paren := p.previous()
+	parameters := p.parameters()
+	arrow := p.consume(tok.ARROW, "Expect '=>' after parameters.")
+
+	if p.match(tok.LEFT_BRACE) {
+		return p.functionExpr(paren, parameters, p.block())
+	}
+	value := p.expression()
+	return p.functionExpr(paren, parameters, []st.Stmt{&st.Return{Keyword: arrow, Value: value}})
+}
+
+func (p *Parser) functionExpr(keyword tok.Token, parameters []tok.Token, body []st.Stmt) exp.Expr {
+	end := p.previous()
+	return &exp.Function{
+		Keyword: keyword,
+		Declaration: &st.Function{
+			Name:   tok.NewToken(tok.IDENTIFIER, []rune("anonymous"), nil, keyword.Line),
+			Params: parameters,
+			Body:   body,
+			End:    end,
+		},
+		End: end,
+	}
+}
+
+func (p *Parser) block() []st.Stmt {
+	statements := make([]st.Stmt, 0)
+
+	for !p.check(tok.RIGHT_BRACE) && !p.isAtEnd() {
+		// fmt.Print(statements)
+		if statement := p.declaration(); statement != nil {
+			statements = append(statements, statement)
+		}
+	}
+	p.consume(tok.RIGHT_BRACE, "Expect '}' after block.")
+	return statements
+}
+
+func (p *Parser) assignment() exp.Expr {
+	// expr := p.equality()
+	expr := p.or()
+
+	if p.match(tok.EQUAL) {
+		equals := p.previous()
+		value := p.assignment()
+
+		if variable, ok := expr.(*exp.Variable); ok {
+			name := variable.Name
+			return &exp.Assign{
+				Name:  name,
+				Value: value,
+			}
+
+		}
+
+		if get, ok := expr.(*exp.Get); ok {
+			return &exp.Set{
+				Object: get.Object,
+				Name:   get.Name,
+				Value:  value,
+			}
+		}
+
+		if index, ok := expr.(*exp.Index); ok {
+			return &exp.IndexSet{
+				Object:  index.Object,
+				Bracket: index.Bracket,
+				Index:   index.Index,
+				Value:   value,
+			}
+		}
+
+		p.errorAt(equals, CodeInvalidAssignment, "Invalid assignment target.")
+	}
+	return expr
+}
+
+func (p *Parser) or() exp.Expr {
+	expr := p.and()
+	for p.match(tok.OR) {
+		operator := p.previous()
+		right := p.and()
+
+		expr = &exp.Logical{
+			Left:     expr,
+			Operator: operator,
+			Right:    right,
+		}
+	}
+
+	return expr
+}
+
+func (p *Parser) and() exp.Expr {
+	expr := p.equality()
+	for p.match(tok.AND) {
+		operator := p.previous()
+		right := p.equality()
+
+		expr = &exp.Logical{
+			Left:     expr,
+			Operator: operator,
+			Right:    right,
+		}
+	}
+
+	return expr
+}
+
+func (p *Parser) equality() exp.Expr {
+	expr := p.comparison()
+
+	for p.match(tok.BANG_EQUAL, tok.EQUAL_EQUAL) {
+		operator := p.previous()
+		right := p.comparison()
+
+		expr = &exp.Binary{
+			Left:     expr,
+			Operator: operator,
+			Right:    right,
+		}
+	}
+
+	return expr
+}
+
+func (p *Parser) comparison() exp.Expr {
+	expr := p.term()
+
+	for p.match(tok.GREATER, tok.GREATER_EQUAL, tok.LESS, tok.LESS_EQUAL) {
+		operator := p.previous()
+		right := p.term()
+
+		// expr = exp.NewBinary(expr, operator, right)
+		expr = &exp.Binary{
+			Left:     expr,
+			Operator: operator,
+			Right:    right,
+		}
+	}
+	return expr
+}
+
+func (p *Parser) term() exp.Expr {
+	expr := p.factor()
+
+	for p.match(tok.MINUS, tok.PLUS) {
+		operator := p.previous()
+		right := p.factor()
+
+		// expr = exp.NewBinary(expr, operator, right)
+		expr = &exp.Binary{
+			Left:     expr,
+			Operator: operator,
+			Right:    right,
+		}
+	}
+	return expr
+}
+
+func (p *Parser) factor() exp.Expr {
+	expr := p.unary()
+
+	for p.match(tok.SLASH, tok.STAR) {
+		operator := p.previous()
+		right := p.unary()
+
+		// expr = exp.NewBinary(expr, operator, right)
+		expr = &exp.Binary{
+			Left:     expr,
+			Operator: operator,
+			Right:    right,
+		}
+	}
+	return expr
+}
+
+func (p *Parser) unary() exp.Expr {
+	if p.match(tok.BANG, tok.MINUS) {
+		operator := p.previous()
+		right := p.unary()
+
+		// return exp.NewUnary(operator, right)
+		return &exp.Unary{
+			Operator: operator,
+			Right:    right,
+		}
+	}
+	// return p.primary()
+	return p.call()
+}
+
+func (p *Parser) finishCall(callee exp.Expr) exp.Expr {
+	arguments := make([]exp.Expr, 0)
+
+	if !p.check(tok.RIGHT_PAREN) {
+		for {
+			if len(arguments) >= 255 {
+				p.errorAt(p.peek(), CodeTooManyArguments, "Can't have more than 255 arguments.")
+			}
+
+			arguments = append(arguments, p.expression())
+			if !p.match(tok.COMMA) {
+				break
+			}
+		}
+
+	}
+
+	paren := p.consume(tok.RIGHT_PAREN, "Expect ')' after arguments.")
+
+	return &exp.Call{
+		Callee:    callee,
+		Paren:     paren,
+		Arguments: arguments,
+	}
+}
+
+func (p *Parser) call() exp.Expr {
+	expr := p.primary()
+	for true {
+		if p.match(tok.LEFT_PAREN) {
+			expr = p.finishCall(expr)
+		} else if p.match(tok.DOT) {
+			name := p.consume(tok.IDENTIFIER, "Expect property name after '.'.")
+			expr = &exp.Get{
+				Object: expr,
+				Name:   name,
+			}
+		} else if p.match(tok.LEFT_BRACKET) {
+			index := p.expression()
+			bracket := p.consume(tok.RIGHT_BRACKET, "Expect ']' after index.")
+			expr = &exp.Index{
+				Object:  expr,
+				Bracket: bracket,
+				Index:   index,
+			}
+		} else {
+			break
+		}
+	}
+	return expr
+}
+
+func (p *Parser) primary() exp.Expr {
+	if p.match(tok.FALSE) {
+		// return exp.NewLiteral(false)
+		return &exp.Literal{
+			Token: p.previous(),
+			Value: false,
+		}
+	}
+	if p.match(tok.TRUE) {
+		// return exp.NewLiteral(true)
+		return &exp.Literal{
+			Token: p.previous(),
+			Value: true,
+		}
+	}
+	if p.match(tok.NIL) {
+		// return exp.NewLiteral(nil)
+		return &exp.Literal{
+			Token: p.previous(),
+			Value: nil}
+	}
+
+	if p.match(tok.NUMBER, tok.STRING) {
+		// return exp.NewLiteral(p.previous().Literal)
+		return &exp.Literal{
+			Token: p.previous(),
+			Value: p.previous().Literal,
+		}
+	}
+
+	if p.match(tok.SUPER) {
+		keyword := p.previous()
+		p.consume(tok.DOT, "Expect '.' after 'super'.")
+		method := p.consume(tok.IDENTIFIER, "Expect superclass method name.")
+		return &exp.Super{
+			Keyword: keyword,
+			Method:  method,
+		}
+	}
+
+	if p.match(tok.THIS) {
+		return &exp.This{
+			Keyword: p.previous(),
+		}
+	}
+
+	if p.match(tok.IDENTIFIER) {
+		return &exp.Variable{
+			Name: p.previous(),
+		}
+	}
+
+	if p.match(tok.LEFT_BRACKET) {
+		start := p.previous()
+		elements := make([]exp.Expr, 0)
+		if !p.check(tok.RIGHT_BRACKET) {
+			for {
+				elements = append(elements, p.expression())
+				if !p.match(tok.COMMA) {
+					break
+				}
+			}
+		}
+		bracket := p.consume(tok.RIGHT_BRACKET, "Expect ']' after list elements.")
+		return &exp.List{
+			Start:    start,
+			Bracket:  bracket,
+			Elements: elements,
+		}
+	}
+
+	// A '{' at the start of a statement is always a block, so by the time
+	// we get here it can only open a map literal.
+	if p.match(tok.LEFT_BRACE) {
+		start := p.previous()
+		keys := make([]exp.Expr, 0)
+		values := make([]exp.Expr, 0)
+		if !p.check(tok.RIGHT_BRACE) {
+			for {
+				keys = append(keys, p.expression())
+				p.consume(tok.COLON, "Expect ':' after map key.")
+				values = append(values, p.expression())
+				if !p.match(tok.COMMA) {
+					break
+				}
+			}
+		}
+		brace := p.consume(tok.RIGHT_BRACE, "Expect '}' after map entries.")
+		return &exp.Map{
+			Start:  start,
+			Brace:  brace,
+			Keys:   keys,
+			Values: values,
+		}
+	}
+
+	if p.match(tok.FUN) {
+		return p.anonymousFunction()
+	}
+
+	if p.check(tok.LEFT_PAREN) && p.arrowAhead() {
+		p.advance()
+		return p.arrowFunction()
+	}
+
+	if p.match(tok.LEFT_PAREN) {
+		start := p.previous()
+		expr := p.expression()
+
+		paren := p.consume(tok.RIGHT_PAREN, "Expect ')' after expression.")
+		// return exp.NewGrouping(expr)
+		return &exp.Grouping{
+			Start:      start,
+			Expression: expr,
+			Paren:      paren,
+		}
+	}
+	// return nil, fmt.Errorf("Expect expression.", p.peek().Line)
+	// return nil, p.Error(p.peek(), "Expect expression.")
+	panic(p.errorAt(p.peek(), CodeExpectedExpression, "Expect expression."))
+}
+
+func (p *Parser) match(types ...tok.TokenType) bool {
+	for _, t := range types {
+		if p.check(t) {
+			p.advance()
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Parser) consume(t tok.TokenType, message string) tok.Token {
+	if p.check(t) {
+		return p.advance()
+	}
+	// panic(message)
+	// panic(err.NewRuntimeError(, message))
+	panic(p.errorAt(p.peek(), CodeExpectedToken, message))
+}
+
+// checkWord reports whether the next token is the identifier word. 'as'
+// and 'from' are only special inside imports, so they aren't keywords and
+// stay free to use as names.
+func (p *Parser) checkWord(word string) bool {
+	return p.check(tok.IDENTIFIER) && string(p.peek().Lexeme) == word
+}
+
+func (p *Parser) check(t tok.TokenType) bool {
+	if p.isAtEnd() {
+		return false
+	}
+	return p.peek().Type == t
+}
+func (p *Parser) advance() tok.Token {
+	if !p.isAtEnd() {
+		p.current++
+	}
+	return p.previous()
+}
+
+func (p *Parser) previous() tok.Token {
+	return p.tokens[p.current-1]
+}
+
+func (p *Parser) peek() tok.Token {
+	return p.tokens[p.current]
+}
+
+func (p *Parser) Error(token tok.Token, message string) (err error) {
+	return p.errorAt(token, CodeSyntax, message)
+}
+
+func (p *Parser) synchronize() {
+	p.advance()
+
+	for !p.isAtEnd() {
+		if p.previous().Type == tok.SEMICOLON {
+			return
+		}
+
+		switch p.peek().Type {
+		case tok.CLASS, tok.FUN, tok.VAR, tok.FOR, tok.IF, tok.WHILE, tok.PRINT, tok.RETURN, tok.THROW, tok.TRY, tok.IMPORT:
+			return
+		}
+		p.advance()
+	}
+}
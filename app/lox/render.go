@@ -0,0 +1,110 @@
+package lox
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	tok "github.com/codecrafters-io/interpreter-starter-go/app/token"
+)
+
+// Render formats e the way ReportError does, but follows each message with
+// the source line it points at, underlines the offending span and adds any
+// notes attached to diagnostics:
+//
+//	[line 2] Error at '+': Operands must be two numbers or two strings.
+//	  |
+//	2 | print "a" + 1;
+//	  |           ^
+//
+// Errors without a position are rendered as plain messages.
+func Render(source string, e error) string {
+	if e == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	if joined, ok := e.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			b.WriteString(renderOne(source, e))
+			b.WriteString("\n")
+		}
+		return b.String()
+	}
+
+	b.WriteString(renderOne(source, e))
+	if ExitCode(e) == 65 {
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func renderOne(source string, e error) string {
+	var span tok.Span
+	var diagnostic *Diagnostic
+	var runtimeError *RuntimeError
+	switch {
+	case errors.As(e, &diagnostic):
+		span = diagnostic.Span
+	case errors.As(e, &runtimeError):
+		span = runtimeError.Span()
+	}
+
+	message := e.Error()
+	if !span.IsValid() || span.Offset > len(source) {
+		return message
+	}
+
+	// The snippet goes right under the first line, above any traceback.
+	first, rest, hasRest := strings.Cut(message, "\n")
+	snippet := Snippet(source, span)
+	if diagnostic != nil {
+		for _, note := range diagnostic.Notes {
+			snippet += "note: " + note.Message + "\n"
+			if note.Span.IsValid() && note.Span.Offset <= len(source) {
+				snippet += Snippet(source, note.Span)
+			}
+		}
+	}
+	if !hasRest {
+		return first + "\n" + strings.TrimSuffix(snippet, "\n")
+	}
+	return first + "\n" + snippet + rest
+}
+
+// Snippet renders the source line containing span with a caret underline
+// beneath the span. Spans running past the end of the line are cut off
+// there.
+func Snippet(source string, span tok.Span) string {
+	lineStart := strings.LastIndexByte(source[:span.Offset], '\n') + 1
+	lineEnd := strings.IndexByte(source[span.Offset:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(source)
+	} else {
+		lineEnd += span.Offset
+	}
+	line := strings.TrimRight(source[lineStart:lineEnd], "\r")
+
+	// Keep tabs in the padding so the caret lines up with the text above.
+	var padding strings.Builder
+	for _, r := range source[lineStart:span.Offset] {
+		if r == '\t' {
+			padding.WriteRune('\t')
+		} else {
+			padding.WriteRune(' ')
+		}
+	}
+
+	end := min(span.End(), lineStart+len(line))
+	width := max(utf8.RuneCountInString(source[span.Offset:max(end, span.Offset)]), 1)
+
+	number := fmt.Sprint(span.Line)
+	gutter := strings.Repeat(" ", len(number))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s |\n", gutter)
+	fmt.Fprintf(&b, "%s | %s\n", number, line)
+	fmt.Fprintf(&b, "%s | %s%s\n", gutter, padding.String(), strings.Repeat("^", width))
+	return b.String()
+}
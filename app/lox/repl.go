@@ -0,0 +1,214 @@
+package lox
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	st "github.com/codecrafters-io/interpreter-starter-go/app/stmt"
+	tok "github.com/codecrafters-io/interpreter-starter-go/app/token"
+)
+
+const replHelp = `Enter Lox statements or expressions. The value of a bare expression is
+printed. Input continues on the next line while brackets or a string are
+left open.
+
+  :cancel       discard unfinished input
+  :load <file>  run a file in the current session
+  :env          list the global variables defined so far
+  :reset        forget all globals and start over
+  :help         show this message
+  :quit         leave the REPL (so does end of input)
+`
+
+// REPL is an interactive session. Globals persist from one input to the
+// next, and errors are reported without ending the session.
+type REPL struct {
+	opts Options
+	vm   *VM
+	// builtins are the globals that exist before any input, which :env
+	// leaves out.
+	builtins map[string]bool
+}
+
+func NewREPL(opts Options) *REPL {
+	if opts.Stdout == nil {
+		opts.Stdout = os.Stdout
+	}
+	if opts.Stderr == nil {
+		opts.Stderr = os.Stderr
+	}
+
+	r := &REPL{opts: opts}
+	r.reset()
+	return r
+}
+
+func (r *REPL) reset() {
+	r.vm = NewVM(r.opts)
+	r.builtins = make(map[string]bool)
+	for name := range r.vm.Globals() {
+		r.builtins[name] = true
+	}
+}
+
+// Run reads input until it is exhausted, ctx is cancelled, or the user
+// quits.
+func (r *REPL) Run(ctx context.Context, in io.Reader) error {
+	lines := bufio.NewScanner(in)
+	var buffer strings.Builder
+
+	for {
+		if buffer.Len() == 0 {
+			fmt.Fprint(r.opts.Stdout, "> ")
+		} else {
+			fmt.Fprint(r.opts.Stdout, "... ")
+		}
+
+		if !lines.Scan() {
+			fmt.Fprintln(r.opts.Stdout)
+			return lines.Err()
+		}
+		if e := ctx.Err(); e != nil {
+			return e
+		}
+		line := lines.Text()
+
+		if buffer.Len() > 0 && strings.TrimSpace(line) == ":cancel" {
+			buffer.Reset()
+			continue
+		}
+		if buffer.Len() == 0 && strings.HasPrefix(strings.TrimSpace(line), ":") {
+			if quit := r.command(ctx, strings.TrimSpace(line)); quit {
+				return nil
+			}
+			continue
+		}
+
+		buffer.WriteString(line)
+		buffer.WriteString("\n")
+		if incomplete(buffer.String()) {
+			continue
+		}
+
+		source := buffer.String()
+		buffer.Reset()
+		if strings.TrimSpace(source) != "" {
+			r.eval(ctx, source)
+		}
+	}
+}
+
+// eval runs one complete input, printing the value if it ends in an
+// expression.
+func (r *REPL) eval(ctx context.Context, source string) {
+	r.vm.source = source
+	statements, e := r.vm.compile(source, true)
+	if e == nil {
+		var value Value
+		value, e = r.vm.execute(ctx, statements, true)
+		if e == nil && len(statements) > 0 {
+			if _, ok := statements[len(statements)-1].(*st.Expression); ok {
+				fmt.Fprintln(r.opts.Stdout, Stringify(value))
+			}
+		}
+	}
+	r.report(e)
+}
+
+func (r *REPL) report(e error) {
+	if e == nil {
+		return
+	}
+	// Runtime errors are printed without a trailing newline.
+	if r.vm.ReportError(e) == 70 {
+		fmt.Fprintln(r.opts.Stderr)
+	}
+}
+
+// command runs a meta-command and reports whether the REPL should exit.
+func (r *REPL) command(ctx context.Context, line string) bool {
+	name, argument, _ := strings.Cut(line, " ")
+	argument = strings.TrimSpace(argument)
+
+	switch name {
+	case ":quit", ":q", ":exit":
+		return true
+	case ":cancel":
+	case ":help", ":h":
+		fmt.Fprint(r.opts.Stdout, replHelp)
+	case ":reset":
+		r.reset()
+		fmt.Fprintln(r.opts.Stdout, "Session reset.")
+	case ":env":
+		r.env()
+	case ":load":
+		if argument == "" {
+			fmt.Fprintln(r.opts.Stderr, "Usage: :load <file>")
+			break
+		}
+		source, e := os.ReadFile(argument)
+		if e != nil {
+			fmt.Fprintf(r.opts.Stderr, "Error reading file: %v\n", e)
+			break
+		}
+		r.report(r.vm.Run(ctx, string(source)))
+	default:
+		fmt.Fprintf(r.opts.Stderr, "Unknown command %s. Type :help for a list.\n", name)
+	}
+	return false
+}
+
+func (r *REPL) env() {
+	globals := r.vm.Globals()
+	names := make([]string, 0, len(globals))
+	for name := range globals {
+		if !r.builtins[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(r.opts.Stdout, "%s = %s\n", name, Stringify(globals[name]))
+	}
+}
+
+// incomplete reports whether source stops in the middle of a string or
+// with brackets left open, meaning more input should be read before it
+// is run. A statement ending inside parentheses is a mistake rather than
+// something to continue, so that is treated as complete.
+func incomplete(source string) bool {
+	scanner := NewScanner([]rune(source))
+	tokens := scanner.ScanTokens()
+	for _, d := range scanner.Diagnostics() {
+		if d.Code == CodeUnterminatedString {
+			return true
+		}
+	}
+
+	open := make([]tok.TokenType, 0)
+	last := tok.EOF
+	for _, token := range tokens {
+		switch token.Type {
+		case tok.LEFT_PAREN, tok.LEFT_BRACE, tok.LEFT_BRACKET:
+			open = append(open, token.Type)
+		case tok.RIGHT_PAREN, tok.RIGHT_BRACE, tok.RIGHT_BRACKET:
+			if len(open) > 0 {
+				open = open[:len(open)-1]
+			}
+		}
+		if token.Type != tok.EOF {
+			last = token.Type
+		}
+	}
+
+	if len(open) == 0 {
+		return false
+	}
+	return !(open[len(open)-1] == tok.LEFT_PAREN && last == tok.SEMICOLON)
+}
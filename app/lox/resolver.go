@@ -0,0 +1,502 @@
+package lox
+
+import (
+	"fmt"
+
+	"github.com/codecrafters-io/interpreter-starter-go/app/token"
+
+	exp "github.com/codecrafters-io/interpreter-starter-go/app/expr"
+	st "github.com/codecrafters-io/interpreter-starter-go/app/stmt"
+)
+
+type FunctionType int
+
+const (
+	FunctionTypeNone FunctionType = iota
+	FunctionTypeFunction
+	FunctionTypeInitializer
+	FunctionTypeMethod
+)
+
+type ClassType int
+
+const (
+	ClassTypeNone ClassType = iota
+	ClassTypeClass
+	ClassTypeSubclass
+)
+
+type ScopeStack []map[string]bool
+
+func (s ScopeStack) isEmpty() bool {
+	return len(s) == 0
+}
+
+func (s ScopeStack) Push(scope map[string]bool) {
+	s = append(s, scope)
+}
+
+func (s ScopeStack) Pop() ScopeStack {
+	return s[:len(s)-1]
+}
+
+func (s ScopeStack) Peek() map[string]bool {
+	return s[len(s)-1]
+}
+
+type Resolver struct {
+	reporter
+	interpreter *Interpreter
+	scopes      ScopeStack
+	// declarations mirrors scopes, remembering where each name was
+	// declared so redeclarations can point back at it.
+	declarations    []map[string]*declaration
+	currentFunction FunctionType
+	currentClass    ClassType
+	// loops counts the loops around the statement being resolved, back to
+	// the nearest function boundary.
+	loops int
+	// lint turns on the warnings behind the lint command; globals holds
+	// the top-level names it has seen, for the shadowing check.
+	lint    bool
+	globals map[string]bool
+	// analysis collects symbols for editor tooling when it is set.
+	analysis *Analysis
+}
+
+type declaration struct {
+	name   token.Token
+	param  bool
+	read   bool
+	symbol *Symbol
+}
+
+func NewResolver(interpreter *Interpreter) *Resolver {
+	return &Resolver{
+		interpreter:     interpreter,
+		scopes:          make(ScopeStack, 0),
+		currentFunction: FunctionTypeNone,
+		currentClass:    ClassTypeNone,
+	}
+}
+
+func (r *Resolver) VisitBlockStmt(stmt *st.Block) any {
+	r.beginScope()
+	r.enter(blockEnd(stmt))
+	r.resolveStmts(stmt.Statements)
+	r.leave()
+	r.endScope()
+	return nil
+}
+
+func (r *Resolver) VisitClassStmt(stmt *st.Class) any {
+	enclosingClass := r.currentClass
+	r.currentClass = ClassTypeClass
+
+	r.declare(stmt.Name)
+	r.define(stmt.Name)
+	r.record(stmt.Name, SymbolClass, initParams(stmt))
+
+	if stmt.Superclass != nil {
+		if string(stmt.Name.Lexeme) == string(stmt.Superclass.Name.Lexeme) {
+			r.errorAt(stmt.Superclass.Name, CodeInheritsFromItself, "A class can't inherit from itself.")
+		}
+
+		r.currentClass = ClassTypeSubclass
+		r.resolveExpr(stmt.Superclass)
+
+		r.beginScope()
+		r.scopes.Peek()["super"] = true
+	}
+
+	r.beginScope()
+	r.scopes.Peek()["this"] = true
+
+	for _, method := range stmt.Methods {
+		declaration := FunctionTypeMethod
+		if string(method.Name.Lexeme) == "init" {
+			declaration = FunctionTypeInitializer
+		}
+		if symbol := r.record(method.Name, SymbolMethod, method.Params); symbol != nil {
+			symbol.Container = string(stmt.Name.Lexeme)
+		}
+		r.resolveFunction(method, declaration)
+	}
+
+	r.endScope()
+
+	if stmt.Superclass != nil {
+		r.endScope()
+	}
+
+	r.currentClass = enclosingClass
+	return nil
+}
+
+func (r *Resolver) VisitExpressionStmt(stmt *st.Expression) any {
+	r.resolveExpr(stmt.Expression)
+	return nil
+}
+
+func (r *Resolver) VisitIfStmt(stmt *st.If) any {
+	if r.lint {
+		r.checkCondition(stmt.Condition)
+	}
+	r.resolveExpr(stmt.Condition)
+	r.resolveStmt(stmt.ThenBranch)
+	if stmt.ElseBranch != nil {
+		r.resolveStmt(stmt.ElseBranch)
+	}
+	return nil
+}
+
+func (r *Resolver) VisitPrintStmt(stmt *st.Print) any {
+	r.resolveExpr(stmt.Expression)
+	return nil
+}
+
+func (r *Resolver) VisitReturnStmt(stmt *st.Return) any {
+
+	if r.currentFunction == FunctionTypeNone {
+		r.errorAt(stmt.Keyword, CodeTopLevelReturn, "Can't return from top-level code.")
+	}
+	if stmt.Value != nil {
+		if r.currentFunction == FunctionTypeInitializer {
+			r.errorAt(stmt.Keyword, CodeInitializerReturn, "Can't return a value from an initializer.")
+		}
+		r.resolveExpr(stmt.Value)
+	}
+	return nil
+}
+
+func (r *Resolver) VisitThrowStmt(stmt *st.Throw) any {
+	r.resolveExpr(stmt.Value)
+	return nil
+}
+
+func (r *Resolver) VisitTryStmt(stmt *st.Try) any {
+	r.resolveStmt(stmt.Body)
+
+	if stmt.Catch != nil {
+		// The error variable shares a scope with the catch block's
+		// statements, the way parameters share one with a function body.
+		r.beginScope()
+		r.enter(stmt.Catch.End.Offset)
+		r.declare(stmt.CatchName)
+		r.define(stmt.CatchName)
+		r.declarations[len(r.declarations)-1][string(stmt.CatchName.Lexeme)].param = true
+		r.record(stmt.CatchName, SymbolVariable, nil)
+		r.resolveStmts(stmt.Catch.Statements)
+		r.leave()
+		r.endScope()
+	}
+
+	if stmt.Finally != nil {
+		r.resolveStmt(stmt.Finally)
+	}
+	return nil
+}
+
+func (r *Resolver) VisitVarStmt(stmt *st.Var) any {
+	r.declare(stmt.Name)
+	r.record(stmt.Name, SymbolVariable, nil)
+
+	if stmt.Initializer != nil {
+		r.resolveExpr(stmt.Initializer)
+	}
+	r.define(stmt.Name)
+	return nil
+}
+
+func (r *Resolver) VisitWhileStmt(stmt *st.While) any {
+	if r.lint {
+		r.checkCondition(stmt.Condition)
+	}
+	r.resolveExpr(stmt.Condition)
+	r.loops++
+	r.resolveStmt(stmt.Body)
+	r.loops--
+	if stmt.Increment != nil {
+		r.resolveExpr(stmt.Increment)
+	}
+	return nil
+}
+
+func (r *Resolver) VisitBreakStmt(stmt *st.Break) any {
+	if r.loops == 0 {
+		r.errorAt(stmt.Keyword, CodeOutsideLoop, "Can't use 'break' outside of a loop.")
+	}
+	return nil
+}
+
+func (r *Resolver) VisitContinueStmt(stmt *st.Continue) any {
+	if r.loops == 0 {
+		r.errorAt(stmt.Keyword, CodeOutsideLoop, "Can't use 'continue' outside of a loop.")
+	}
+	return nil
+}
+
+// VisitImportStmt declares the names an import binds. Imports bind
+// globals, so they are only allowed outside functions and blocks.
+func (r *Resolver) VisitImportStmt(stmt *st.Import) any {
+	if len(r.scopes) > 0 {
+		r.errorAt(stmt.Keyword, CodeImportNotTopLevel, "Can only import at the top level.")
+	}
+	for _, name := range importedNames(stmt) {
+		r.declare(name)
+		r.record(name, SymbolVariable, nil)
+		r.define(name)
+	}
+	return nil
+}
+
+func (r *Resolver) VisitFunctionStmt(stmt *st.Function) any {
+	r.declare(stmt.Name)
+	r.define(stmt.Name)
+	r.record(stmt.Name, SymbolFunction, stmt.Params)
+	r.resolveFunction(stmt, FunctionTypeFunction)
+	return nil
+}
+
+func (r *Resolver) VisitVariableExpr(expr *exp.Variable) any {
+	if len(r.scopes) > 0 {
+		if defined, declared := r.scopes.Peek()[string(expr.Name.Lexeme)]; declared && !defined {
+			r.errorAt(expr.Name, CodeReadInInitializer, "Can't read local variable in its own initializer.")
+		}
+	}
+	r.resolveLocal(expr, expr.Name)
+	return nil
+}
+
+func (r *Resolver) VisitAssignExpr(expr *exp.Assign) any {
+	if r.lint {
+		r.checkSelfAssignment(expr)
+	}
+	r.resolveExpr(expr.Value)
+	r.resolveLocal(expr, expr.Name)
+	return nil
+}
+
+func (r *Resolver) VisitBinaryExpr(expr *exp.Binary) any {
+	r.resolveExpr(expr.Left)
+	r.resolveExpr(expr.Right)
+	return nil
+}
+
+func (r *Resolver) VisitCallExpr(expr *exp.Call) any {
+	if r.lint {
+		r.checkCallee(expr)
+	}
+	r.resolveExpr(expr.Callee)
+	for _, arg := range expr.Arguments {
+		r.resolveExpr(arg)
+	}
+	return nil
+}
+
+func (r *Resolver) VisitGroupingExpr(expr *exp.Grouping) any {
+	r.resolveExpr(expr.Expression)
+	return nil
+}
+
+func (r *Resolver) VisitLiteralExpr(expr *exp.Literal) any {
+	return nil
+}
+
+func (r *Resolver) VisitLogicalExpr(expr *exp.Logical) any {
+	r.resolveExpr(expr.Left)
+	r.resolveExpr(expr.Right)
+	return nil
+}
+
+func (r *Resolver) VisitGetExpr(expr *exp.Get) any {
+	r.resolveExpr(expr.Object)
+	return nil
+}
+
+func (r *Resolver) VisitSetExpr(expr *exp.Set) any {
+	if r.lint {
+		r.checkSelfAssignment(expr)
+	}
+	r.resolveExpr(expr.Value)
+	r.resolveExpr(expr.Object)
+	return nil
+}
+
+func (r *Resolver) VisitListExpr(expr *exp.List) any {
+	for _, element := range expr.Elements {
+		r.resolveExpr(element)
+	}
+	return nil
+}
+
+func (r *Resolver) VisitIndexExpr(expr *exp.Index) any {
+	r.resolveExpr(expr.Object)
+	r.resolveExpr(expr.Index)
+	return nil
+}
+
+func (r *Resolver) VisitIndexSetExpr(expr *exp.IndexSet) any {
+	r.resolveExpr(expr.Value)
+	r.resolveExpr(expr.Object)
+	r.resolveExpr(expr.Index)
+	return nil
+}
+
+func (r *Resolver) VisitMapExpr(expr *exp.Map) any {
+	for n, key := range expr.Keys {
+		r.resolveExpr(key)
+		r.resolveExpr(expr.Values[n])
+	}
+	return nil
+}
+
+func (r *Resolver) VisitFunctionExpr(expr *exp.Function) any {
+	r.resolveFunction(expr.Declaration.(*st.Function), FunctionTypeFunction)
+	return nil
+}
+
+func (r *Resolver) VisitThisExpr(expr *exp.This) any {
+	if r.currentClass == ClassTypeNone {
+		r.errorAt(expr.Keyword, CodeThisOutsideClass, "Can't use 'this' outside of a class.")
+		return nil
+	}
+
+	r.resolveLocal(expr, expr.Keyword)
+	return nil
+}
+
+func (r *Resolver) VisitSuperExpr(expr *exp.Super) any {
+	if r.currentClass == ClassTypeNone {
+		r.errorAt(expr.Keyword, CodeSuperOutsideClass, "Can't use 'super' outside of a class.")
+	} else if r.currentClass != ClassTypeSubclass {
+		r.errorAt(expr.Keyword, CodeSuperWithoutSuperclass, "Can't use 'super' in a class with no superclass.")
+	}
+
+	r.resolveLocal(expr, expr.Keyword)
+	return nil
+}
+
+func (r *Resolver) VisitUnaryExpr(expr *exp.Unary) any {
+	r.resolveExpr(expr.Right)
+	return nil
+}
+
+func (r *Resolver) declare(name token.Token) {
+	if len(r.scopes) == 0 {
+		if r.lint {
+			r.globals[string(name.Lexeme)] = true
+		}
+		return
+	}
+
+	scope := r.scopes.Peek()
+
+	_, exists := scope[string(name.Lexeme)]
+	if exists {
+		d := r.errorAt(name, CodeRedeclared, "Already a variable with this name in this scope.")
+		previous := r.declarations[len(r.declarations)-1][string(name.Lexeme)].name
+		d.Notes = append(d.Notes, Note{
+			Message: fmt.Sprintf("'%s' was first declared on line %d.", string(name.Lexeme), previous.Line),
+			Span:    previous.Span(),
+		})
+	}
+	scope[string(name.Lexeme)] = false
+	r.declarations[len(r.declarations)-1][string(name.Lexeme)] = &declaration{name: name}
+
+	// r.scopes.Peek()[string(name.Lexeme)] = false
+
+}
+
+func (r *Resolver) define(name token.Token) {
+	if len(r.scopes) == 0 {
+		return
+	}
+	r.scopes.Peek()[string(name.Lexeme)] = true
+}
+
+func (r *Resolver) resolveLocal(expr exp.Expr, name token.Token) {
+	for i := len(r.scopes) - 1; i >= 0; i-- {
+		if r.scopes[i][string(name.Lexeme)] {
+			d := r.declarations[i][string(name.Lexeme)]
+			if d != nil {
+				if _, ok := expr.(*exp.Variable); ok {
+					d.read = true
+				}
+			}
+			r.use(name, d)
+			r.interpreter.Resolve(expr, len(r.scopes)-1-i)
+			return
+		}
+	}
+	r.use(name, nil)
+}
+
+// Resolve binds every local variable use in statements to its scope depth.
+func (r *Resolver) Resolve(statements []st.Stmt) {
+	r.resolveStmts(statements)
+}
+
+func (r *Resolver) resolveStmts(statements []st.Stmt) {
+	if r.lint {
+		r.checkUnreachable(statements)
+	}
+	for _, statement := range statements {
+		statement.Accept(r)
+	}
+}
+
+func (r *Resolver) resolveFunction(fn *st.Function, typ FunctionType) {
+	enclosingFunction, enclosingLoops := r.currentFunction, r.loops
+	r.currentFunction, r.loops = typ, 0
+
+	r.beginScope()
+	r.enter(fn.End.Offset)
+	for _, param := range fn.Params {
+		if r.lint {
+			r.checkShadowing(param)
+		}
+		r.declare(param)
+		r.define(param)
+		r.declarations[len(r.declarations)-1][string(param.Lexeme)].param = true
+		r.record(param, SymbolParameter, nil)
+	}
+	r.resolveStmts(fn.Body)
+	r.leave()
+	r.endScope()
+
+	r.currentFunction, r.loops = enclosingFunction, enclosingLoops
+}
+
+func (r *Resolver) resolveStmt(statement st.Stmt) {
+	statement.Accept(r)
+}
+
+func (r *Resolver) resolveExpr(expr exp.Expr) {
+	expr.Accept(r)
+}
+func (r *Resolver) beginScope() {
+	r.scopes = append(r.scopes, make(map[string]bool))
+	r.declarations = append(r.declarations, make(map[string]*declaration))
+}
+func (r *Resolver) endScope() {
+	if r.lint {
+		r.checkUnused(r.declarations[len(r.declarations)-1])
+	}
+	r.scopes = r.scopes[:len(r.scopes)-1]
+	r.declarations = r.declarations[:len(r.declarations)-1]
+}
+
+var _ exp.ExprVisitor = (*Resolver)(nil)
+var _ st.StmtVisitor = (*Resolver)(nil)
+
+// initParams returns the parameters of a class's init method, which are
+// the arguments the class is called with.
+func initParams(class *st.Class) []token.Token {
+	for _, method := range class.Methods {
+		if string(method.Name.Lexeme) == "init" {
+			return method.Params
+		}
+	}
+	return nil
+}
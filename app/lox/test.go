@@ -0,0 +1,204 @@
+package lox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	err "github.com/codecrafters-io/interpreter-starter-go/app/err"
+	st "github.com/codecrafters-io/interpreter-starter-go/app/stmt"
+)
+
+// AssertionError is returned by RunTest when one of the assertion natives
+// failed, as opposed to the test crashing with some other runtime error.
+type AssertionError struct {
+	*RuntimeError
+}
+
+func (e *AssertionError) Unwrap() error {
+	return e.RuntimeError
+}
+
+// TestNames lists the top-level functions in source whose names start
+// with "test", in the order they are declared.
+func TestNames(source string) ([]string, error) {
+	scanner := NewScanner([]rune(source))
+	parser := NewParser(scanner.ScanTokens())
+	statements := parser.Parse()
+	if e := joinErrors(append(scanner.Errors(), parser.Errors()...)); e != nil {
+		return nil, e
+	}
+
+	names := make([]string, 0)
+	for _, statement := range statements {
+		if function, ok := statement.(*st.Function); ok && strings.HasPrefix(string(function.Name.Lexeme), "test") {
+			names = append(names, string(function.Name.Lexeme))
+		}
+	}
+	return names, nil
+}
+
+// RunTest runs source as a program on a fresh tree-walking interpreter,
+// with assert, assertEqual and assertError defined, then calls the
+// top-level function name with no arguments. The test passed if it
+// returns nil.
+func RunTest(ctx context.Context, source string, name string, opts Options) error {
+	opts.Engine = EngineTreeWalker
+	v := NewVM(opts)
+	t := &tester{interpreter: v.interpreter}
+	t.define()
+
+	if e := v.Run(ctx, source); e != nil {
+		return t.result(e)
+	}
+	return t.result(v.interpreter.callGlobal(ctx, name))
+}
+
+// tester holds the assertion natives for one test.
+type tester struct {
+	interpreter *Interpreter
+	// failed is set when an assertion fails, so the error it causes can
+	// be told apart from other runtime errors.
+	failed bool
+}
+
+func (t *tester) result(e error) error {
+	var runtimeError *RuntimeError
+	if t.failed && errors.As(e, &runtimeError) {
+		return &AssertionError{runtimeError}
+	}
+	return e
+}
+
+func (t *tester) define() {
+	globals := &t.interpreter.Globals
+	globals.Define("assert", NewNativeFunction("assert", Variadic, t.assert))
+	globals.Define("assertEqual", NewNativeFunction("assertEqual", 2, t.assertEqual))
+	globals.Define("assertError", NewNativeFunction("assertError", 1, t.assertError))
+}
+
+func (t *tester) fail(format string, args ...any) error {
+	t.failed = true
+	return fmt.Errorf(format, args...)
+}
+
+// assert(condition) or assert(condition, message).
+func (t *tester) assert(args []any) (any, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, fmt.Errorf("Expected 1 or 2 arguments but got %d.", len(args))
+	}
+	if isTruthy(args[0]) {
+		return nil, nil
+	}
+	if len(args) == 2 {
+		return nil, t.fail("Assertion failed: %s", stringfy(args[1]))
+	}
+	return nil, t.fail("Assertion failed.")
+}
+
+func (t *tester) assertEqual(args []any) (any, error) {
+	expected, actual := args[0], args[1]
+	if isEqual(expected, actual) {
+		return nil, nil
+	}
+	message := fmt.Sprintf("Expected %s but got %s.", describe(expected), describe(actual))
+	if describe(expected) == describe(actual) {
+		// Lists, maps and instances are equal only to themselves, so the
+		// message has to say why two that print the same are not.
+		kind := typeName(expected) + "s"
+		if kind == "classs" {
+			kind = "classes"
+		}
+		message = fmt.Sprintf("Expected %s but got %s (different %s with equal contents).", describe(expected), describe(actual), kind)
+	} else if isRune(expected) && isRune(actual) {
+		message += fmt.Sprintf(" They differ at character %d.", mismatch(expected.([]rune), actual.([]rune))+1)
+	}
+	return nil, t.fail("%s", message)
+}
+
+// assertError calls a function that takes no arguments and fails unless
+// it raises a runtime error. The error's message is returned.
+func (t *tester) assertError(args []any) (any, error) {
+	function, ok := args[0].(LoxCallable)
+	if !ok || function.arity() != 0 {
+		return nil, errors.New("assertError expects a function that takes no arguments.")
+	}
+
+	message, raised := t.raises(function)
+	if !raised {
+		return nil, t.fail("Expected %s to raise an error.", stringfy(function))
+	}
+	return []rune(message), nil
+}
+
+// raises calls function and reports whether it panicked with a runtime
+// error. Failed assertions inside it count as errors too.
+func (t *tester) raises(function LoxCallable) (message string, raised bool) {
+	i := t.interpreter
+	failed := t.failed
+	depth := len(i.frames)
+	defer func() {
+		if r := recover(); r != nil {
+			runtimeError, ok := r.(*err.RuntimeError)
+			if !ok {
+				panic(r)
+			}
+			i.frames = i.frames[:depth]
+			t.failed = failed
+			message, raised = runtimeError.Message(), true
+		}
+	}()
+
+	function.call(i, nil)
+	return "", false
+}
+
+// describe formats a value for an assertion message. Strings are quoted
+// so "1" and 1 look different.
+func describe(value any) string {
+	if isRune(value) {
+		return strconv.Quote(string(value.([]rune)))
+	}
+	return stringfy(value)
+}
+
+// mismatch is the index of the first character where a and b differ.
+func mismatch(a, b []rune) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+// callGlobal calls the global function name with no arguments, the way a
+// call expression in top-level code would.
+func (i *Interpreter) callGlobal(ctx context.Context, name string) (e error) {
+	i.ctx = ctx
+	defer func() {
+		i.ctx = context.Background()
+		if r := recover(); r != nil {
+			e = i.recoverError(r)
+		}
+	}()
+
+	value, ok := i.Globals.Lookup(name)
+	function, callable := value.(LoxCallable)
+	if !ok || !callable {
+		return fmt.Errorf("%s is not a function.", name)
+	}
+	if function.arity() != 0 {
+		return fmt.Errorf("%s must not take any arguments.", name)
+	}
+
+	line := 0
+	if declared, ok := function.(*LoxFunction); ok {
+		line = declared.declaration.Name.Line
+	}
+	i.frames = append(i.frames, callFrame{function: frameName(function), line: line, environment: i.enviroment})
+	function.call(i, nil)
+	i.frames = i.frames[:len(i.frames)-1]
+	return nil
+}
@@ -1,4 +1,4 @@
-package main
+package lox
 
 import (
 	"fmt"
@@ -71,7 +71,16 @@ func isEqual(left, right interface{}) bool {
 	if isBool(left) && isBool(right) {
 		return left.(bool) == right.(bool)
 	}
-	return false
+	if isRune(left) || isRune(right) {
+		return false
+	}
+	// Functions, classes and instances compare by identity.
+	return left == right
+}
+
+// Stringify formats a value the way print does.
+func Stringify(value Value) string {
+	return stringfy(value)
 }
 
 func stringfy(obj any) string {
@@ -96,3 +105,9 @@ func stringfy(obj any) string {
 type Return struct {
 	Value any
 }
+
+// Break and Continue unwind to the innermost loop, the way Return unwinds
+// to the function call.
+type Break struct{}
+
+type Continue struct{}
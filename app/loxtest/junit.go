@@ -0,0 +1,112 @@
+package loxtest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// The JUnit XML schema understood by Jenkins, GitLab and GitHub Actions
+// reporters.
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.6f", d.Seconds())
+}
+
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(text, "\n")
+	return line
+}
+
+// WriteJUnit writes the results as JUnit XML for CI servers. A file that
+// could not be parsed shows up as a test named after it with an error.
+func WriteJUnit(w io.Writer, suites []*Suite) error {
+	report := junitSuites{}
+	var total time.Duration
+	for _, s := range suites {
+		passed, failed, errored := s.Counts()
+		suite := junitSuite{
+			Name:     s.File,
+			Tests:    passed + failed + errored,
+			Failures: failed,
+			Errors:   errored,
+			Time:     seconds(s.Duration),
+		}
+		if s.Err != nil {
+			suite.Cases = append(suite.Cases, junitCase{
+				Name:      s.File,
+				Classname: s.File,
+				Time:      seconds(0),
+				Error:     &junitProblem{Message: firstLine(s.Err.Error()), Text: s.Err.Error()},
+			})
+		}
+		for _, c := range s.Cases {
+			testcase := junitCase{
+				Name:      c.Name,
+				Classname: s.File,
+				Time:      seconds(c.Duration),
+				SystemOut: c.Output,
+			}
+			if c.Err != nil {
+				problem := &junitProblem{Message: firstLine(c.Err.Error()), Text: c.Err.Error()}
+				if c.Failed() {
+					testcase.Failure = problem
+				} else {
+					testcase.Error = problem
+				}
+			}
+			suite.Cases = append(suite.Cases, testcase)
+		}
+
+		report.Suites = append(report.Suites, suite)
+		report.Tests += suite.Tests
+		report.Failures += failed
+		report.Errors += errored
+		total += s.Duration
+	}
+	report.Time = seconds(total)
+
+	if _, e := io.WriteString(w, xml.Header); e != nil {
+		return e
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if e := encoder.Encode(report); e != nil {
+		return e
+	}
+	_, e := io.WriteString(w, "\n")
+	return e
+}
@@ -0,0 +1,179 @@
+// Package loxtest runs tests written in Lox. A test is a top-level
+// function whose name starts with "test" in a file ending in _test.lox.
+// Each one runs in a fresh interpreter, so tests cannot see each other's
+// globals.
+package loxtest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/codecrafters-io/interpreter-starter-go/app/lox"
+)
+
+// Case is the result of one test function.
+type Case struct {
+	Name     string
+	Duration time.Duration
+	// Output is what the test printed.
+	Output string
+	// Err is nil when the test passed, a *lox.AssertionError when an
+	// assertion failed, or whatever other error stopped it.
+	Err error
+}
+
+// Failed reports whether an assertion failed, as opposed to the test
+// crashing.
+func (c *Case) Failed() bool {
+	var assertion *lox.AssertionError
+	return errors.As(c.Err, &assertion)
+}
+
+// Suite is the tests of one file.
+type Suite struct {
+	File     string
+	Cases    []*Case
+	Duration time.Duration
+	// Err is set when the file could not be read or parsed, in which case
+	// none of its tests ran.
+	Err error
+}
+
+// Counts returns how many tests passed, had an assertion fail and crashed.
+func (s *Suite) Counts() (passed, failed, errored int) {
+	for _, c := range s.Cases {
+		switch {
+		case c.Err == nil:
+			passed++
+		case c.Failed():
+			failed++
+		default:
+			errored++
+		}
+	}
+	if s.Err != nil {
+		errored++
+	}
+	return
+}
+
+// Discover finds the test files under paths. Directories are searched
+// recursively; files are taken as they are.
+func Discover(paths []string) ([]string, error) {
+	seen := make(map[string]bool)
+	files := make([]string, 0)
+	add := func(file string) {
+		if !seen[file] {
+			seen[file] = true
+			files = append(files, file)
+		}
+	}
+
+	for _, path := range paths {
+		info, e := os.Stat(path)
+		if e != nil {
+			return nil, e
+		}
+		if !info.IsDir() {
+			add(path)
+			continue
+		}
+		e = filepath.WalkDir(path, func(file string, entry fs.DirEntry, e error) error {
+			if e != nil {
+				return e
+			}
+			if !entry.IsDir() && strings.HasSuffix(file, "_test.lox") {
+				add(file)
+			}
+			return nil
+		})
+		if e != nil {
+			return nil, e
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Run runs the tests in file whose names match filter, or all of them if
+// filter is nil.
+func Run(ctx context.Context, file string, filter *regexp.Regexp) *Suite {
+	suite := &Suite{File: file}
+	start := time.Now()
+	defer func() { suite.Duration = time.Since(start) }()
+
+	source, e := os.ReadFile(file)
+	if e != nil {
+		suite.Err = e
+		return suite
+	}
+	names, e := lox.TestNames(string(source))
+	if e != nil {
+		suite.Err = e
+		return suite
+	}
+
+	for _, name := range names {
+		if filter != nil && !filter.MatchString(name) {
+			continue
+		}
+		var output bytes.Buffer
+		began := time.Now()
+		e := lox.RunTest(ctx, string(source), name, lox.Options{Stdout: &output, Stderr: &output, File: file})
+		suite.Cases = append(suite.Cases, &Case{
+			Name:     name,
+			Duration: time.Since(began),
+			Output:   output.String(),
+			Err:      e,
+		})
+	}
+	return suite
+}
+
+// WriteReport prints a line for each test and the output and error of the
+// ones that did not pass, then a line for the file.
+func (s *Suite) WriteReport(w io.Writer) {
+	if s.Err != nil {
+		fmt.Fprintf(w, "ERROR %s\n%s\n", s.File, indent(s.Err.Error()))
+		return
+	}
+	for _, c := range s.Cases {
+		status := "PASS"
+		if c.Err != nil {
+			status = "FAIL"
+		}
+		fmt.Fprintf(w, "--- %s: %s (%s)\n", status, c.Name, milliseconds(c.Duration))
+		if c.Err != nil {
+			if c.Output != "" {
+				fmt.Fprint(w, indent(c.Output))
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintln(w, indent(c.Err.Error()))
+		}
+	}
+
+	passed, failed, errored := s.Counts()
+	status := "ok  "
+	if failed+errored > 0 {
+		status = "FAIL"
+	}
+	fmt.Fprintf(w, "%s %s (%d passed, %d failed, %d errored, %s)\n", status, s.File, passed, failed, errored, milliseconds(s.Duration))
+}
+
+func indent(text string) string {
+	return "    " + strings.ReplaceAll(strings.TrimSuffix(text, "\n"), "\n", "\n    ")
+}
+
+func milliseconds(d time.Duration) string {
+	return fmt.Sprintf("%.3fms", float64(d)/float64(time.Millisecond))
+}
@@ -0,0 +1,188 @@
+package lsp
+
+import (
+	"unicode/utf8"
+
+	tok "github.com/codecrafters-io/interpreter-starter-go/app/token"
+)
+
+// The subset of the Language Server Protocol types the server uses. Field
+// names follow the specification.
+
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+type Location struct {
+	URI   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+type TextDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+type TextDocumentItem struct {
+	URI     string `json:"uri"`
+	Version int    `json:"version"`
+	Text    string `json:"text"`
+}
+
+type TextDocumentPositionParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+}
+
+type DidOpenTextDocumentParams struct {
+	TextDocument TextDocumentItem `json:"textDocument"`
+}
+
+type DidChangeTextDocumentParams struct {
+	TextDocument   TextDocumentIdentifier `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+}
+
+type DidCloseTextDocumentParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+type ReferenceParams struct {
+	TextDocumentPositionParams
+	Context struct {
+		IncludeDeclaration bool `json:"includeDeclaration"`
+	} `json:"context"`
+}
+
+type DocumentSymbolParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+type Diagnostic struct {
+	Range    Range  `json:"range"`
+	Severity int    `json:"severity"`
+	Code     string `json:"code,omitempty"`
+	Source   string `json:"source"`
+	Message  string `json:"message"`
+}
+
+type PublishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+type MarkupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+type Hover struct {
+	Contents MarkupContent `json:"contents"`
+	Range    Range         `json:"range"`
+}
+
+type SymbolInformation struct {
+	Name          string   `json:"name"`
+	Kind          int      `json:"kind"`
+	Location      Location `json:"location"`
+	ContainerName string   `json:"containerName,omitempty"`
+}
+
+type CompletionItem struct {
+	Label  string `json:"label"`
+	Kind   int    `json:"kind"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Enumerations from the specification.
+const (
+	severityError       = 1
+	severityWarning     = 2
+	severityInformation = 3
+
+	symbolKindClass    = 5
+	symbolKindMethod   = 6
+	symbolKindFunction = 12
+
+	completionKindFunction = 3
+	completionKindVariable = 6
+	completionKindClass    = 7
+	completionKindKeyword  = 14
+
+	textDocumentSyncFull = 1
+)
+
+// document is an open file. LSP positions count UTF-16 code units within a
+// line, while tokens carry byte offsets, so it converts between the two.
+type document struct {
+	text string
+	// lines holds the byte offset at which each line starts.
+	lines []int
+}
+
+func newDocument(text string) *document {
+	lines := []int{0}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			lines = append(lines, i+1)
+		}
+	}
+	return &document{text: text, lines: lines}
+}
+
+func (d *document) offset(p Position) int {
+	if p.Line >= len(d.lines) {
+		return len(d.text)
+	}
+	offset := d.lines[p.Line]
+	for units := 0; units < p.Character && offset < len(d.text) && d.text[offset] != '\n'; {
+		r, size := utf8.DecodeRuneInString(d.text[offset:])
+		offset += size
+		units += utf16Len(r)
+	}
+	return offset
+}
+
+func (d *document) position(offset int) Position {
+	line := 0
+	for line+1 < len(d.lines) && d.lines[line+1] <= offset {
+		line++
+	}
+	character := 0
+	for _, r := range d.text[d.lines[line]:min(offset, len(d.text))] {
+		character += utf16Len(r)
+	}
+	return Position{Line: line, Character: character}
+}
+
+func (d *document) spanRange(span tok.Span) Range {
+	return Range{Start: d.position(span.Offset), End: d.position(span.End())}
+}
+
+// lineRange covers a whole 1-based line, for diagnostics without a span.
+func (d *document) lineRange(line int) Range {
+	start := Position{Line: max(line-1, 0)}
+	end := start
+	if line-1 < len(d.lines) {
+		lineEnd := len(d.text)
+		if line < len(d.lines) {
+			lineEnd = d.lines[line] - 1
+		}
+		end = d.position(lineEnd)
+	}
+	return Range{Start: start, End: end}
+}
+
+func utf16Len(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,415 @@
+// Package lsp implements a Language Server Protocol server for Lox. It
+// speaks JSON-RPC over a pair of streams, normally stdin and stdout, and
+// answers from lox.Analyze.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/interpreter-starter-go/app/lox"
+	tok "github.com/codecrafters-io/interpreter-starter-go/app/token"
+)
+
+// JSON-RPC error codes.
+const (
+	codeParseError     = -32700
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInvalidRequest = -32600
+)
+
+type request struct {
+	ID     *json.RawMessage `json:"id"`
+	Method string           `json:"method"`
+	Params json.RawMessage  `json:"params"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+type Server struct {
+	in        *bufio.Reader
+	out       io.Writer
+	documents map[string]*document
+	analyses  map[string]*lox.Analysis
+	shutdown  bool
+}
+
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{
+		in:        bufio.NewReader(in),
+		out:       out,
+		documents: make(map[string]*document),
+		analyses:  make(map[string]*lox.Analysis),
+	}
+}
+
+// Run serves requests until the client sends exit or closes the input. It
+// reports whether shutdown was requested first, which decides the exit
+// status the specification asks for.
+func (s *Server) Run() (bool, error) {
+	for {
+		body, e := s.read()
+		if e == io.EOF {
+			return s.shutdown, nil
+		}
+		if e != nil {
+			return s.shutdown, e
+		}
+
+		var r request
+		if e := json.Unmarshal(body, &r); e != nil {
+			s.respond(nil, nil, &rpcError{codeParseError, e.Error()})
+			continue
+		}
+		if r.Method == "exit" {
+			return s.shutdown, nil
+		}
+
+		result, e := s.handle(r)
+		if r.ID == nil {
+			continue
+		}
+		var failure *rpcError
+		if e != nil {
+			failure = &rpcError{codeInvalidParams, e.Error()}
+			if rpc, ok := e.(*rpcError); ok {
+				failure = rpc
+			}
+		}
+		if e := s.respond(r.ID, result, failure); e != nil {
+			return s.shutdown, e
+		}
+	}
+}
+
+// read returns the body of the next message, framed by a Content-Length
+// header.
+func (s *Server) read() ([]byte, error) {
+	header, e := textproto.NewReader(s.in).ReadMIMEHeader()
+	if e != nil {
+		return nil, e
+	}
+	length, e := strconv.Atoi(header.Get("Content-Length"))
+	if e != nil {
+		return nil, fmt.Errorf("bad Content-Length: %w", e)
+	}
+	body := make([]byte, length)
+	_, e = io.ReadFull(s.in, body)
+	return body, e
+}
+
+func (s *Server) write(message map[string]any) error {
+	message["jsonrpc"] = "2.0"
+	body, e := json.Marshal(message)
+	if e != nil {
+		return e
+	}
+	_, e = fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return e
+}
+
+func (s *Server) respond(id *json.RawMessage, result any, failure *rpcError) error {
+	message := map[string]any{"id": id}
+	if failure != nil {
+		message["error"] = failure
+	} else {
+		message["result"] = result
+	}
+	return s.write(message)
+}
+
+func (s *Server) notify(method string, params any) error {
+	return s.write(map[string]any{"method": method, "params": params})
+}
+
+func (s *Server) handle(r request) (any, error) {
+	switch r.Method {
+	case "initialize":
+		return map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync":       textDocumentSyncFull,
+				"definitionProvider":     true,
+				"referencesProvider":     true,
+				"hoverProvider":          true,
+				"documentSymbolProvider": true,
+				"completionProvider":     map[string]any{},
+			},
+			"serverInfo": map[string]any{"name": "lox"},
+		}, nil
+	case "initialized", "$/cancelRequest", "$/setTrace":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if e := json.Unmarshal(r.Params, &params); e != nil {
+			return nil, e
+		}
+		return nil, s.update(params.TextDocument.URI, params.TextDocument.Text)
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if e := json.Unmarshal(r.Params, &params); e != nil {
+			return nil, e
+		}
+		if len(params.ContentChanges) == 0 {
+			return nil, nil
+		}
+		// Full sync: the last change holds the whole text.
+		return nil, s.update(params.TextDocument.URI, params.ContentChanges[len(params.ContentChanges)-1].Text)
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if e := json.Unmarshal(r.Params, &params); e != nil {
+			return nil, e
+		}
+		delete(s.documents, params.TextDocument.URI)
+		delete(s.analyses, params.TextDocument.URI)
+		return nil, s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+			URI:         params.TextDocument.URI,
+			Diagnostics: []Diagnostic{},
+		})
+
+	case "textDocument/definition":
+		return withPosition(s, r, s.definition)
+	case "textDocument/references":
+		var params ReferenceParams
+		if e := json.Unmarshal(r.Params, &params); e != nil {
+			return nil, e
+		}
+		return s.references(params)
+	case "textDocument/hover":
+		return withPosition(s, r, s.hover)
+	case "textDocument/completion":
+		return withPosition(s, r, s.completion)
+	case "textDocument/documentSymbol":
+		var params DocumentSymbolParams
+		if e := json.Unmarshal(r.Params, &params); e != nil {
+			return nil, e
+		}
+		return s.symbols(params.TextDocument.URI)
+	}
+
+	if r.ID == nil {
+		// Unknown notifications are ignored, as the specification asks.
+		return nil, nil
+	}
+	return nil, &rpcError{codeMethodNotFound, "Unknown method " + r.Method + "."}
+}
+
+func withPosition(s *Server, r request, handler func(uri string, d *document, a *lox.Analysis, offset int) (any, error)) (any, error) {
+	var params TextDocumentPositionParams
+	if e := json.Unmarshal(r.Params, &params); e != nil {
+		return nil, e
+	}
+	d, a, e := s.lookup(params.TextDocument.URI)
+	if e != nil {
+		return nil, e
+	}
+	return handler(params.TextDocument.URI, d, a, d.offset(params.Position))
+}
+
+func (s *Server) lookup(uri string) (*document, *lox.Analysis, error) {
+	d, ok := s.documents[uri]
+	if !ok {
+		return nil, nil, &rpcError{codeInvalidRequest, "Document " + uri + " is not open."}
+	}
+	return d, s.analyses[uri], nil
+}
+
+// update re-analyses a document and publishes its diagnostics.
+func (s *Server) update(uri string, text string) error {
+	d := newDocument(text)
+	a := lox.Analyze(text)
+	s.documents[uri] = d
+	s.analyses[uri] = a
+
+	diagnostics := make([]Diagnostic, 0, len(a.Diagnostics))
+	for _, diagnostic := range a.Diagnostics {
+		r := d.lineRange(diagnostic.Line)
+		if diagnostic.Span.IsValid() {
+			r = d.spanRange(diagnostic.Span)
+		}
+		severity := severityError
+		switch diagnostic.Severity {
+		case lox.SeverityWarning:
+			severity = severityWarning
+		case lox.SeverityNote:
+			severity = severityInformation
+		}
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    r,
+			Severity: severity,
+			Code:     diagnostic.Code,
+			Source:   "lox",
+			Message:  diagnostic.Message,
+		})
+	}
+	return s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{URI: uri, Diagnostics: diagnostics})
+}
+
+func (s *Server) location(uri string, d *document, t tok.Token) Location {
+	return Location{URI: uri, Range: d.spanRange(t.Span())}
+}
+
+func (s *Server) definition(uri string, d *document, a *lox.Analysis, offset int) (any, error) {
+	symbol := a.SymbolAt(offset)
+	if symbol == nil || symbol.Kind == lox.SymbolBuiltin {
+		return nil, nil
+	}
+	return s.location(uri, d, symbol.Name), nil
+}
+
+func (s *Server) references(params ReferenceParams) (any, error) {
+	uri := params.TextDocument.URI
+	d, a, e := s.lookup(uri)
+	if e != nil {
+		return nil, e
+	}
+	symbol := a.SymbolAt(d.offset(params.Position))
+	if symbol == nil {
+		return nil, nil
+	}
+
+	locations := make([]Location, 0, len(symbol.References)+1)
+	if params.Context.IncludeDeclaration && symbol.Kind != lox.SymbolBuiltin {
+		locations = append(locations, s.location(uri, d, symbol.Name))
+	}
+	for _, use := range symbol.References {
+		locations = append(locations, s.location(uri, d, use))
+	}
+	return locations, nil
+}
+
+func (s *Server) hover(uri string, d *document, a *lox.Analysis, offset int) (any, error) {
+	symbol := a.SymbolAt(offset)
+	if symbol == nil {
+		return nil, nil
+	}
+
+	value := "```lox\n" + signature(symbol) + "\n```"
+	if symbol.Arity >= 0 {
+		value += fmt.Sprintf("\n\nArity: %d", symbol.Arity)
+	}
+	return Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: value},
+		Range:    d.spanRange(tokenAt(symbol, offset).Span()),
+	}, nil
+}
+
+// tokenAt returns the occurrence of symbol that covers offset.
+func tokenAt(symbol *lox.Symbol, offset int) tok.Token {
+	for _, use := range symbol.References {
+		if offset >= use.Offset && offset <= use.Offset+use.Length {
+			return use
+		}
+	}
+	return symbol.Name
+}
+
+func signature(symbol *lox.Symbol) string {
+	name := string(symbol.Name.Lexeme)
+	params := make([]string, len(symbol.Params))
+	for i, param := range symbol.Params {
+		params[i] = string(param.Lexeme)
+	}
+	switch symbol.Kind {
+	case lox.SymbolFunction:
+		return "fun " + name + "(" + strings.Join(params, ", ") + ")"
+	case lox.SymbolMethod:
+		return symbol.Container + "." + name + "(" + strings.Join(params, ", ") + ")"
+	case lox.SymbolClass:
+		return "class " + name + "(" + strings.Join(params, ", ") + ")"
+	case lox.SymbolBuiltin:
+		return "<native fn " + name + ">"
+	case lox.SymbolParameter:
+		return "(parameter) " + name
+	default:
+		return "var " + name
+	}
+}
+
+func (s *Server) completion(uri string, d *document, a *lox.Analysis, offset int) (any, error) {
+	items := make([]CompletionItem, 0)
+	seen := make(map[string]bool)
+	add := func(item CompletionItem) {
+		if !seen[item.Label] {
+			seen[item.Label] = true
+			items = append(items, item)
+		}
+	}
+
+	// Later declarations are the more deeply nested ones, so they win when
+	// names are shadowed.
+	visible := a.Visible(offset)
+	for i := len(visible) - 1; i >= 0; i-- {
+		add(CompletionItem{Label: string(visible[i].Name.Lexeme), Kind: completionKind(visible[i]), Detail: signature(visible[i])})
+	}
+	for _, builtin := range a.Builtins {
+		add(CompletionItem{Label: string(builtin.Name.Lexeme), Kind: completionKindFunction, Detail: signature(builtin)})
+	}
+
+	keywords := make([]string, 0, len(tok.Keywords))
+	for keyword := range tok.Keywords {
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+	for _, keyword := range keywords {
+		add(CompletionItem{Label: keyword, Kind: completionKindKeyword})
+	}
+	return items, nil
+}
+
+func completionKind(symbol *lox.Symbol) int {
+	switch symbol.Kind {
+	case lox.SymbolFunction, lox.SymbolBuiltin:
+		return completionKindFunction
+	case lox.SymbolClass:
+		return completionKindClass
+	default:
+		return completionKindVariable
+	}
+}
+
+func (s *Server) symbols(uri string) (any, error) {
+	d, a, e := s.lookup(uri)
+	if e != nil {
+		return nil, e
+	}
+
+	symbols := make([]SymbolInformation, 0)
+	for _, symbol := range a.Symbols {
+		kind := 0
+		switch symbol.Kind {
+		case lox.SymbolFunction:
+			kind = symbolKindFunction
+		case lox.SymbolMethod:
+			kind = symbolKindMethod
+		case lox.SymbolClass:
+			kind = symbolKindClass
+		default:
+			continue
+		}
+		symbols = append(symbols, SymbolInformation{
+			Name:          string(symbol.Name.Lexeme),
+			Kind:          kind,
+			Location:      s.location(uri, d, symbol.Name),
+			ContainerName: symbol.Container,
+		})
+	}
+	return symbols, nil
+}
@@ -1,57 +1,92 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
-
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/codecrafters-io/interpreter-starter-go/app/coverage"
+	"github.com/codecrafters-io/interpreter-starter-go/app/dap"
+	"github.com/codecrafters-io/interpreter-starter-go/app/debug"
+	"github.com/codecrafters-io/interpreter-starter-go/app/format"
+	"github.com/codecrafters-io/interpreter-starter-go/app/lox"
+	"github.com/codecrafters-io/interpreter-starter-go/app/loxtest"
+	"github.com/codecrafters-io/interpreter-starter-go/app/lsp"
 	printer "github.com/codecrafters-io/interpreter-starter-go/app/printer"
-	tok "github.com/codecrafters-io/interpreter-starter-go/app/token"
+	"github.com/codecrafters-io/interpreter-starter-go/app/profile"
 )
 
 var hadError = false
 
 var hadRuntimeError = false
 
-func report(line int, where string, message string) {
-	// fmt.Printf("[line %d] Error: %s\n", line, message)
-	fmt.Fprintf(os.Stderr, "[line %d] Error%s: %s\n", line, where, message)
+// showSource is set when stderr is a terminal, so people get the offending
+// line quoted under each error while scripts and tests still see the
+// plain one-line format.
+var showSource = isTerminal(os.Stderr)
 
-	hadError = true
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
 }
 
-func Error(token tok.Token, message string) {
-	// report(line, "", message)
-	if token.Type == tok.EOF {
-		report(token.Line, " at end", message)
-	} else {
-		report(token.Line, fmt.Sprintf(" at '%s'", string(token.Lexeme)), message)
+// reportDiagnostics prints diagnostics once a phase has finished with the
+// file. Only errors make the command fail.
+func reportDiagnostics(source []rune, diagnostics []*lox.Diagnostic) {
+	for _, d := range diagnostics {
+		if showSource {
+			fmt.Fprint(os.Stderr, lox.Render(string(source), d))
+		} else {
+			fmt.Fprintln(os.Stderr, d.Error())
+		}
+		if d.Severity == lox.SeverityError {
+			hadError = true
+		}
 	}
 }
 
-func run(source string) {
-	fmt.Println(source)
+// writeProfile saves a pprof profile for go tool pprof.
+func writeProfile(path string, profiler *profile.Profiler) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := profiler.WritePprof(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
 
-	for _, char := range source {
-		token := string(char)
-		fmt.Println("Symbol: ", token)
+// writeLCOV saves a coverage tracefile.
+func writeLCOV(path string, c *coverage.Coverage) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := c.WriteLCOV(f); err != nil {
+		f.Close()
+		return err
 	}
+	return f.Close()
 }
 
-func runPrompt() {
-	input := ""
-	for {
-		fmt.Print(">> ")
-		_, err := fmt.Scanln(&input)
-		if err != nil {
-			fmt.Println("Error reading input:", err)
-			continue
-		}
-		if input == "exit" {
-			break
-		}
-		run(input)
-		hadError = false
+// writeJUnit saves test results for CI.
+func writeJUnit(path string, suites []*loxtest.Suite) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := loxtest.WriteJUnit(f, suites); err != nil {
+		f.Close()
+		return err
 	}
+	return f.Close()
 }
 
 type LoxHandler func([]rune)
@@ -86,6 +121,37 @@ func runFile(filename string, handler LoxHandler) {
 }
 
 func main() {
+	if len(os.Args) == 2 && os.Args[1] == "repl" {
+		repl := lox.NewREPL(lox.Options{ShowSource: showSource})
+		if err := repl.Run(context.Background(), os.Stdin); err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	if len(os.Args) == 2 && os.Args[1] == "lsp" {
+		shutdown, err := lsp.NewServer(os.Stdin, os.Stdout).Run()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error serving LSP: %v\n", err)
+			os.Exit(1)
+		}
+		// The specification has the server exit with 1 when it was not
+		// asked to shut down first.
+		if !shutdown {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	if len(os.Args) == 2 && os.Args[1] == "dap" {
+		if err := dap.NewServer(os.Stdin, os.Stdout).Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error serving DAP: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	if len(os.Args) < 3 {
 		fmt.Fprintln(os.Stderr, "Usage: ./your_program.sh tokenize <filename>")
 		os.Exit(1)
@@ -95,26 +161,34 @@ func main() {
 	filename := os.Args[2]
 
 	switch command {
-	case "repl":
-		runPrompt()
 	case "tokenize":
 		runFile(filename, func(source []rune) {
-			s := NewScanner(source)
+			s := lox.NewScanner(source)
 
 			tokens := s.ScanTokens()
 
 			for _, token := range tokens {
-				fmt.Printf(token.String())
+				fmt.Print(token.String())
 			}
+			reportDiagnostics(source, s.Diagnostics())
 		})
 
 	case "parse":
-		runFile(filename, func(source []rune) {
-			s := NewScanner(source)
+		flags := flag.NewFlagSet("parse", flag.ExitOnError)
+		program := flags.Bool("program", false, "parse a whole program instead of a single expression")
+		format := flags.String("format", "sexpr", "output format: sexpr or json")
+		flags.Parse(os.Args[2:])
+		if flags.NArg() < 1 || (*format != "sexpr" && *format != "json") {
+			fmt.Fprintln(os.Stderr, "Usage: ./your_program.sh parse [--program] [--format=sexpr|json] <filename>")
+			os.Exit(1)
+		}
+
+		runFile(flags.Arg(0), func(source []rune) {
+			s := lox.NewScanner(source)
 
 			tokens := s.ScanTokens()
 
-			p := NewParser(tokens)
+			p := lox.NewParser(tokens)
 
 			defer func() {
 				if r := recover(); r != nil {
@@ -123,10 +197,38 @@ func main() {
 					hadError = true
 
 				}
+				reportDiagnostics(source, append(s.Diagnostics(), p.Diagnostics()...))
 			}()
+
+			if *program {
+				statements := p.Parse()
+				if s.HadError() || p.HadError() {
+					return
+				}
+				if *format == "json" {
+					out, err := printer.NewJSONPrinter().PrintProgram(statements)
+					if err != nil {
+						panic(err)
+					}
+					fmt.Println(string(out))
+					return
+				}
+				fmt.Print(printer.NewAstPrinter().PrintProgram(statements))
+				return
+			}
+
 			expr := p.ParseExpression()
 
-			if hadError {
+			if s.HadError() || p.HadError() {
+				return
+			}
+
+			if *format == "json" {
+				out, err := printer.NewJSONPrinter().Print(expr)
+				if err != nil {
+					panic(err)
+				}
+				fmt.Println(string(out))
 				return
 			}
 
@@ -139,10 +241,10 @@ func main() {
 		return
 	case "evaluate", "eval":
 		runFile(filename, func(source []rune) {
-			s := NewScanner(source)
+			s := lox.NewScanner(source)
 			tokens := s.ScanTokens()
 
-			p := NewParser(tokens)
+			p := lox.NewParser(tokens)
 			defer func() {
 				if r := recover(); r != nil {
 					// fmt.Println("recovered")
@@ -150,47 +252,268 @@ func main() {
 					hadError = true
 
 				}
+				reportDiagnostics(source, append(s.Diagnostics(), p.Diagnostics()...))
 			}()
 			expr := p.ParseExpression()
 
-			if hadError {
+			if s.HadError() || p.HadError() {
 				return
 			}
-			interpreter := NewInterpreter()
-			interpreter.InterpretExpression(expr)
-			if hadRuntimeError {
+			interpreter := lox.NewInterpreter(os.Stdout)
+			value, e := interpreter.InterpretExpression(expr)
+			if e != nil {
+				if showSource {
+					fmt.Fprint(os.Stderr, lox.Render(string(source), e))
+				} else {
+					fmt.Fprint(os.Stderr, e.Error())
+				}
+				hadRuntimeError = true
 				return
 			}
+			fmt.Print(lox.Stringify(value))
 		})
 		return
 
 	case "run":
+		flags := flag.NewFlagSet("run", flag.ExitOnError)
+		useVM := flags.Bool("vm", false, "compile to bytecode and run on the stack VM")
+		profiling := flags.Bool("profile", false, "print where the program spent its time to stderr")
+		pprofPath := flags.String("pprof", "", "write a pprof profile to this file")
+		covering := flags.Bool("coverage", false, "print the source annotated with line and branch coverage to stderr")
+		lcovPath := flags.String("lcov", "lcov.info", "where --coverage writes its LCOV tracefile, or empty for none")
+		searchPath := flags.String("path", os.Getenv("LOX_PATH"), "directories to look for imported modules in, separated by '"+string(os.PathListSeparator)+"'")
+		flags.Parse(os.Args[2:])
+		if flags.NArg() < 1 || (*useVM && (*profiling || *pprofPath != "" || *covering)) {
+			fmt.Fprintln(os.Stderr, "Usage: ./your_program.sh run [--vm | --profile | --coverage] [--pprof=file] [--lcov=file] [--path=dirs] <filename>")
+			os.Exit(1)
+		}
 
-		runFile(filename, func(source []rune) {
-			s := NewScanner(source)
-			tokens := s.ScanTokens()
-
-			p := NewParser(tokens)
-			statements := p.Parse()
+		runFile(flags.Arg(0), func(source []rune) {
+			options := lox.Options{ShowSource: showSource, File: flags.Arg(0), SearchPath: filepath.SplitList(*searchPath)}
+			if *useVM {
+				options.Engine = lox.EngineBytecode
+			}
+			if *profiling || *pprofPath != "" {
+				options.Profiler = profile.New(flags.Arg(0))
+			}
+			if *covering {
+				options.Coverage = coverage.New(flags.Arg(0))
+			}
 
-			if hadError {
-				return
+			machine := lox.NewVM(options)
+			switch machine.ReportError(machine.Run(context.Background(), string(source))) {
+			case 65:
+				hadError = true
+			case 70:
+				hadRuntimeError = true
 			}
 
-			interpreter := NewInterpreter()
-			resolver := NewResolver(*interpreter)
+			// Reports follow any runtime error, which has no newline of
+			// its own.
+			if hadRuntimeError && (*profiling || *covering) {
+				fmt.Fprintln(os.Stderr)
+			}
+			if options.Profiler != nil {
+				options.Profiler.Stop()
+				if *profiling {
+					fmt.Fprintln(os.Stderr)
+					options.Profiler.WriteTable(os.Stderr)
+				}
+				if *pprofPath != "" {
+					if err := writeProfile(*pprofPath, options.Profiler); err != nil {
+						fmt.Fprintf(os.Stderr, "Error writing profile: %v\n", err)
+						os.Exit(1)
+					}
+				}
+			}
+			if options.Coverage != nil {
+				fmt.Fprintln(os.Stderr)
+				options.Coverage.WriteReport(os.Stderr, string(source))
+				if *lcovPath != "" {
+					if err := writeLCOV(*lcovPath, options.Coverage); err != nil {
+						fmt.Fprintf(os.Stderr, "Error writing coverage: %v\n", err)
+						os.Exit(1)
+					}
+				}
+			}
+		})
+		return
 
-			if hadError {
+	case "debug":
+		runFile(filename, func(source []rune) {
+			cli := debug.NewCLI(filename, string(source), os.Stdin, os.Stdout)
+			machine := lox.NewVM(lox.Options{ShowSource: showSource, Hook: cli.Hook, File: filename})
+			e := machine.Run(context.Background(), string(source))
+			if errors.Is(e, debug.ErrQuit) {
 				return
 			}
-			resolver.resolveStmts(statements)
-			interpreter.Interpret(statements)
-			if hadRuntimeError {
-				return
+			switch machine.ReportError(e) {
+			case 65:
+				hadError = true
+			case 70:
+				hadRuntimeError = true
+				fmt.Fprintln(os.Stderr)
+			default:
+				fmt.Println("Program finished.")
 			}
 		})
 		return
 
+	case "test":
+		flags := flag.NewFlagSet("test", flag.ExitOnError)
+		run := flags.String("run", "", "only run tests whose names match this regular expression")
+		junitPath := flags.String("junit", "", "write the results as JUnit XML to this file")
+		flags.Parse(os.Args[2:])
+		if flags.NArg() < 1 {
+			fmt.Fprintln(os.Stderr, "Usage: ./your_program.sh test [--run=regexp] [--junit=file] <dir or file>...")
+			os.Exit(1)
+		}
+
+		var filter *regexp.Regexp
+		if *run != "" {
+			var err error
+			if filter, err = regexp.Compile(*run); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid --run pattern: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		files, err := loxtest.Discover(flags.Args())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error finding tests: %v\n", err)
+			os.Exit(1)
+		}
+		if len(files) == 0 {
+			fmt.Println("No test files found.")
+			os.Exit(0)
+		}
+
+		suites := make([]*loxtest.Suite, 0, len(files))
+		passed, failed := 0, 0
+		start := time.Now()
+		for _, file := range files {
+			suite := loxtest.Run(context.Background(), file, filter)
+			suite.WriteReport(os.Stdout)
+			suites = append(suites, suite)
+			p, f, e := suite.Counts()
+			passed, failed = passed+p, failed+f+e
+		}
+		fmt.Printf("\n%d passed, %d failed in %.3fms\n", passed, failed, float64(time.Since(start))/float64(time.Millisecond))
+
+		if *junitPath != "" {
+			if err := writeJUnit(*junitPath, suites); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing JUnit report: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if failed > 0 {
+			os.Exit(1)
+		}
+		os.Exit(0)
+
+	case "fmt":
+		flags := flag.NewFlagSet("fmt", flag.ExitOnError)
+		check := flags.Bool("check", false, "list files whose formatting differs and exit 1 if there are any")
+		write := flags.Bool("w", false, "write the result back to the file instead of printing it")
+		flags.Parse(os.Args[2:])
+		if flags.NArg() < 1 {
+			fmt.Fprintln(os.Stderr, "Usage: ./your_program.sh fmt [--check] [-w] <filename>...")
+			os.Exit(1)
+		}
+
+		changed := false
+		for _, filename := range flags.Args() {
+			fileContents, err := os.ReadFile(filename)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading file: %v\n", err)
+				os.Exit(1)
+			}
+			source := []rune(string(fileContents))
+
+			formatted, diagnostics := format.Source(source)
+			if len(diagnostics) > 0 {
+				reportDiagnostics(source, diagnostics)
+				continue
+			}
+			if !*check && !*write {
+				fmt.Print(formatted)
+				continue
+			}
+			if formatted == string(fileContents) {
+				continue
+			}
+
+			changed = true
+			if *check {
+				fmt.Println(filename)
+			}
+			if *write {
+				if err := os.WriteFile(filename, []byte(formatted), 0644); err != nil {
+					fmt.Fprintf(os.Stderr, "Error writing file: %v\n", err)
+					os.Exit(1)
+				}
+			}
+		}
+
+		if hadError {
+			os.Exit(65)
+		}
+		if changed && *check {
+			os.Exit(1)
+		}
+		os.Exit(0)
+
+	case "lint":
+		flags := flag.NewFlagSet("lint", flag.ExitOnError)
+		configPath := flags.String("config", ".loxlint.json", "JSON file listing rules to disable")
+		listRules := flags.Bool("rules", false, "list the lint rules and exit")
+		flags.Parse(os.Args[2:])
+		if *listRules {
+			for _, rule := range lox.LintRules {
+				fmt.Printf("%s  %-20s %s\n", rule.Code, rule.Name, rule.Description)
+			}
+			os.Exit(0)
+		}
+		if flags.NArg() < 1 {
+			fmt.Fprintln(os.Stderr, "Usage: ./your_program.sh lint [--config=file] [--rules] <filename>...")
+			os.Exit(1)
+		}
+
+		var config lox.LintConfig
+		if _, err := os.Stat(*configPath); err == nil {
+			if config, err = lox.ReadLintConfig(*configPath); err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading config: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		warned := false
+		for _, filename := range flags.Args() {
+			fileContents, err := os.ReadFile(filename)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading file: %v\n", err)
+				os.Exit(1)
+			}
+			diagnostics := lox.Lint(string(fileContents), config)
+			if flags.NArg() > 1 && len(diagnostics) > 0 {
+				fmt.Fprintf(os.Stderr, "%s:\n", filename)
+			}
+			for _, d := range diagnostics {
+				if d.Severity == lox.SeverityWarning {
+					warned = true
+					d.Message += " [" + lox.RuleName(d.Code) + "]"
+				}
+			}
+			reportDiagnostics([]rune(string(fileContents)), diagnostics)
+		}
+
+		if hadError {
+			os.Exit(65)
+		}
+		if warned {
+			os.Exit(1)
+		}
+		os.Exit(0)
+
 	default:
 		fmt.Fprintln(os.Stderr, "Unknown command:", command)
 
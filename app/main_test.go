@@ -0,0 +1,377 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// The conformance suite runs each program under testdata/<command> through
+// the command line tool, the way the reference Lox test runner does, and
+// compares what it prints with comments in the program:
+//
+//	print 1 + 2; // expect: 3
+//	print nil.x; // expect runtime error: Only instances have properties.
+//	// [line 4] Error at ';': Expect expression.
+//	var; // Error at ';': Expect variable name.
+//
+// Expected output must match stdout line for line. A runtime error must be
+// the first line of stderr, reported on the line of its comment, and the
+// tool must exit with 70. Syntax errors must be exactly the lines of
+// stderr, and the tool must exit with 65.
+
+// runMain makes the test binary act as the lox command line tool, so the
+// suite needs no separate build step.
+const runMain = "LOX_CONFORMANCE_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(runMain) == "1" {
+		main()
+		return
+	}
+	os.Exit(m.Run())
+}
+
+var (
+	expectOutput       = regexp.MustCompile(`// expect: ?(.*)`)
+	expectRuntimeError = regexp.MustCompile(`// expect runtime error: (.+)`)
+	expectSyntaxError  = regexp.MustCompile(`// (\[line (\d+)\] )?(Error.*)`)
+)
+
+type expectation struct {
+	output []string
+	// errors are the syntax errors, in the order they are reported.
+	errors       []string
+	runtimeError string
+	runtimeLine  int
+}
+
+func (e *expectation) exitCode() int {
+	switch {
+	case len(e.errors) > 0:
+		return 65
+	case e.runtimeError != "":
+		return 70
+	default:
+		return 0
+	}
+}
+
+func parseExpectations(t *testing.T, source string) expectation {
+	var e expectation
+	for n, line := range strings.Split(source, "\n") {
+		if match := expectRuntimeError.FindStringSubmatch(line); match != nil {
+			e.runtimeError, e.runtimeLine = match[1], n+1
+		} else if match := expectOutput.FindStringSubmatch(line); match != nil {
+			e.output = append(e.output, match[1])
+		} else if match := expectSyntaxError.FindStringSubmatch(line); match != nil {
+			at := n + 1
+			if match[2] != "" {
+				at, _ = strconv.Atoi(match[2])
+			}
+			e.errors = append(e.errors, fmt.Sprintf("[line %d] %s", at, match[3]))
+		}
+	}
+	if e.runtimeError != "" && len(e.errors) > 0 {
+		t.Fatal("a program cannot expect both syntax and runtime errors")
+	}
+	return e
+}
+
+func lines(output string) []string {
+	if output == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+}
+
+func runLox(t *testing.T, args ...string) (stdout string, stderr string, code int) {
+	t.Helper()
+	return runLoxInput(t, "", args...)
+}
+
+// runLoxInput runs the tool like runLox, with input on its stdin.
+func runLoxInput(t *testing.T, input string, args ...string) (stdout string, stderr string, code int) {
+	t.Helper()
+	command := exec.Command(os.Args[0], args...)
+	command.Env = append(os.Environ(), runMain+"=1")
+	command.Stdin = strings.NewReader(input)
+	var out, errOut bytes.Buffer
+	command.Stdout, command.Stderr = &out, &errOut
+	e := command.Run()
+
+	var exit *exec.ExitError
+	if errors.As(e, &exit) {
+		code = exit.ExitCode()
+	} else if e != nil {
+		t.Fatalf("running lox: %v", e)
+	}
+	return out.String(), errOut.String(), code
+}
+
+func TestConformance(t *testing.T) {
+	suites := []struct {
+		dir  string
+		args []string
+	}{
+		{"tokenize", []string{"tokenize"}},
+		{"parse", []string{"parse"}},
+		{"evaluate", []string{"evaluate"}},
+		{"run", []string{"run"}},
+		// The bytecode VM has to agree with the tree-walker.
+		{"run", []string{"run", "--vm"}},
+	}
+
+	for _, suite := range suites {
+		files, e := filepath.Glob(filepath.Join("testdata", suite.dir, "*.lox"))
+		if e != nil {
+			t.Fatal(e)
+		}
+		if len(files) == 0 {
+			t.Fatalf("no programs in testdata/%s", suite.dir)
+		}
+
+		for _, file := range files {
+			name := strings.Join(suite.args, " ") + "/" + strings.TrimSuffix(filepath.Base(file), ".lox")
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+				source, e := os.ReadFile(file)
+				if e != nil {
+					t.Fatal(e)
+				}
+				expected := parseExpectations(t, string(source))
+				stdout, stderr, code := runLox(t, append(suite.args, file)...)
+				check(t, expected, stdout, stderr, code)
+			})
+		}
+	}
+}
+
+// TestDebugger feeds commands to the debug prompt and checks where the
+// program stops.
+func TestDebugger(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		commands []string
+		stops    []string
+	}{
+		{
+			name:     "breakpoint in a loop stops on every iteration",
+			file:     "loop.lox",
+			commands: []string{"break 3", "c", "p i", "c", "p i", "c", "p i", "c"},
+			stops: []string{
+				"Stopped at entry, line 1 in <script>.",
+				"Breakpoint at line 3 in <script>.", "0",
+				"Breakpoint at line 3 in <script>.", "1",
+				"Breakpoint at line 3 in <script>.", "2",
+				"3",
+				"Program finished.",
+			},
+		},
+		{
+			name:     "breakpoint on a recursive call stops in every frame",
+			file:     "loop.lox",
+			commands: []string{"break 6", "c", "c", "p n", "c", "p n", "c", "p n", "c"},
+			stops: []string{
+				"Stopped at entry, line 1 in <script>.",
+				"Breakpoint at line 6 in <script>.",
+				"Breakpoint at line 6 in count.", "2",
+				"Breakpoint at line 6 in count.", "1",
+				"Breakpoint at line 6 in count.", "0",
+				"3",
+				"Program finished.",
+			},
+		},
+		{
+			name:     "breakpoint in the program does not stop in an imported file",
+			file:     "imports.lox",
+			commands: []string{"break 4", "c", "c"},
+			stops: []string{
+				"Stopped at entry, line 1 in <script>.",
+				"Breakpoint at line 4 in <script>.",
+				"10",
+				"Program finished.",
+			},
+		},
+		{
+			name:     "breakpoint in an imported file",
+			file:     "imports.lox",
+			commands: []string{"break modules/counter.lox:4", "c", "p n", "c"},
+			stops: []string{
+				"Stopped at entry, line 1 in <script>.",
+				"Breakpoint at line 4 of modules/counter.lox in bump.", "0",
+				"10",
+				"Program finished.",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			input := strings.Join(test.commands, "\n") + "\n"
+			stdout, stderr, code := runLoxInput(t, input, "debug", filepath.Join("testdata", "debug", test.file))
+			if stderr != "" || code != 0 {
+				t.Fatalf("exit code %d, stderr:\n%s", code, stderr)
+			}
+
+			// Leave out the prompts, echoed source and confirmations.
+			got := make([]string, 0)
+			for _, line := range lines(stdout) {
+				line = strings.TrimSpace(strings.ReplaceAll(line, "(debug) ", ""))
+				if strings.Contains(line, " | ") || strings.HasPrefix(line, "Breakpoint set") || line == "" {
+					continue
+				}
+				line, _, _ = strings.Cut(line, " Type help")
+				got = append(got, line)
+			}
+			if !equal(got, test.stops) {
+				t.Errorf("stops:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(test.stops, "\n"))
+			}
+		})
+	}
+}
+
+func TestDAPBreakpoints(t *testing.T) {
+	file := filepath.Join("testdata", "debug", "loop.lox")
+	var input strings.Builder
+	for n, message := range []map[string]any{
+		{"command": "initialize"},
+		{"command": "launch", "arguments": map[string]any{"program": file}},
+		// Line 4 only closes the loop body and line 5 is blank.
+		{"command": "setBreakpoints", "arguments": map[string]any{
+			"source":      map[string]any{"path": file},
+			"breakpoints": []map[string]any{{"line": 3}, {"line": 4}, {"line": 5}},
+		}},
+		{"command": "disconnect"},
+	} {
+		message["seq"], message["type"] = n+1, "request"
+		body, e := json.Marshal(message)
+		if e != nil {
+			t.Fatal(e)
+		}
+		fmt.Fprintf(&input, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	}
+
+	stdout, stderr, code := runLoxInput(t, input.String(), "dap")
+	if stderr != "" || code != 0 {
+		t.Fatalf("exit code %d, stderr:\n%s", code, stderr)
+	}
+
+	var got []bool
+	for _, frame := range strings.Split(stdout, "Content-Length: ")[1:] {
+		_, body, _ := strings.Cut(frame, "\r\n\r\n")
+		var message struct {
+			Command string
+			Body    struct {
+				Breakpoints []struct{ Verified bool }
+			}
+		}
+		if e := json.Unmarshal([]byte(body), &message); e != nil {
+			t.Fatalf("bad message %q: %v", body, e)
+		}
+		if message.Command == "setBreakpoints" {
+			for _, breakpoint := range message.Body.Breakpoints {
+				got = append(got, breakpoint.Verified)
+			}
+		}
+	}
+	if want := []bool{true, false, false}; !reflect.DeepEqual(got, want) {
+		t.Errorf("verified %v, want %v", got, want)
+	}
+}
+
+func TestProfileNamesModuleFiles(t *testing.T) {
+	_, stderr, code := runLox(t, "run", "--profile", filepath.Join("testdata", "run", "modules.lox"))
+	if code != 0 {
+		t.Fatalf("exit code %d, stderr:\n%s", code, stderr)
+	}
+	// Functions and lines in imported modules are named with their file.
+	for _, want := range []string{"square (modules/shapes.lox:18)", "\nmodules/shapes.lox:19 "} {
+		if !strings.Contains(stderr, want) {
+			t.Errorf("profile is missing %q:\n%s", want, stderr)
+		}
+	}
+}
+
+func check(t *testing.T, expected expectation, stdout string, stderr string, code int) {
+	t.Helper()
+	if got := lines(stdout); !equal(got, expected.output) {
+		t.Errorf("stdout:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(expected.output, "\n"))
+	}
+
+	errorLines := lines(stderr)
+	switch {
+	case len(expected.errors) > 0:
+		if !equal(errorLines, expected.errors) {
+			t.Errorf("stderr:\n%s\nwant:\n%s", stderr, strings.Join(expected.errors, "\n"))
+		}
+	case expected.runtimeError != "":
+		prefix := fmt.Sprintf("[line %d] Error", expected.runtimeLine)
+		if len(errorLines) == 0 || !strings.HasPrefix(errorLines[0], prefix) || !strings.HasSuffix(errorLines[0], ": "+expected.runtimeError) {
+			t.Errorf("stderr:\n%s\nwant a runtime error on line %d: %s", stderr, expected.runtimeLine, expected.runtimeError)
+		}
+	default:
+		if stderr != "" {
+			t.Errorf("unexpected stderr:\n%s", stderr)
+		}
+	}
+
+	if code != expected.exitCode() {
+		t.Errorf("exit code %d, want %d", code, expected.exitCode())
+	}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for n := range a {
+		if a[n] != b[n] {
+			return false
+		}
+	}
+	return true
+}
+
+// TestFormat runs fmt on each program under testdata/fmt and compares the
+// result with the .golden file beside it, which must itself be formatted.
+func TestFormat(t *testing.T) {
+	files, e := filepath.Glob(filepath.Join("testdata", "fmt", "*.lox"))
+	if e != nil {
+		t.Fatal(e)
+	}
+	if len(files) == 0 {
+		t.Fatal("no programs in testdata/fmt")
+	}
+
+	for _, file := range files {
+		golden := strings.TrimSuffix(file, ".lox") + ".golden"
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			t.Parallel()
+			want, e := os.ReadFile(golden)
+			if e != nil {
+				t.Fatal(e)
+			}
+			for _, input := range []string{file, golden} {
+				stdout, stderr, code := runLox(t, "fmt", input)
+				if code != 0 || stderr != "" {
+					t.Fatalf("fmt %s exited with %d:\n%s", input, code, stderr)
+				}
+				if stdout != string(want) {
+					t.Errorf("fmt %s:\n%s\nwant:\n%s", input, stdout, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package printer
+
+import (
+	"encoding/json"
+
+	exp "github.com/codecrafters-io/interpreter-starter-go/app/expr"
+	st "github.com/codecrafters-io/interpreter-starter-go/app/stmt"
+	"github.com/codecrafters-io/interpreter-starter-go/app/token"
+)
+
+// node is one AST node in the JSON export. Every node has a "kind" naming
+// its Go type and a "span" locating it in the source; the remaining keys
+// are the node's children and attributes.
+type node map[string]any
+
+// JSONPrinter serialises a program as JSON for external tools.
+type JSONPrinter struct {
+}
+
+func NewJSONPrinter() *JSONPrinter {
+	return &JSONPrinter{}
+}
+
+// PrintProgram returns the indented JSON for a list of statements.
+func (p *JSONPrinter) PrintProgram(statements []st.Stmt) ([]byte, error) {
+	return json.MarshalIndent(node{
+		"kind":       "Program",
+		"statements": p.stmts(statements),
+	}, "", "  ")
+}
+
+// Print returns the indented JSON for a single expression.
+func (p *JSONPrinter) Print(expr exp.Expr) ([]byte, error) {
+	return json.MarshalIndent(p.expr(expr), "", "  ")
+}
+
+func span(s token.Span) node {
+	return node{
+		"line":   s.Line,
+		"column": s.Column,
+		"offset": s.Offset,
+		"length": s.Length,
+	}
+}
+
+func name(t token.Token) node {
+	return node{
+		"name": string(t.Lexeme),
+		"span": span(t.Span()),
+	}
+}
+
+func (p *JSONPrinter) expr(expr exp.Expr) any {
+	if expr == nil {
+		return nil
+	}
+	n := expr.Accept(p).(node)
+	n["span"] = span(exp.Span(expr))
+	return n
+}
+
+func (p *JSONPrinter) exprs(exprs []exp.Expr) []any {
+	nodes := make([]any, len(exprs))
+	for i, expr := range exprs {
+		nodes[i] = p.expr(expr)
+	}
+	return nodes
+}
+
+func (p *JSONPrinter) stmt(stmt st.Stmt) any {
+	if stmt == nil {
+		return nil
+	}
+	n := stmt.Accept(p).(node)
+	n["span"] = span(st.Span(stmt))
+	return n
+}
+
+func (p *JSONPrinter) stmts(stmts []st.Stmt) []any {
+	nodes := make([]any, len(stmts))
+	for i, stmt := range stmts {
+		nodes[i] = p.stmt(stmt)
+	}
+	return nodes
+}
+
+func (p *JSONPrinter) VisitBinaryExpr(expr *exp.Binary) any {
+	return node{"kind": "Binary", "operator": string(expr.Operator.Lexeme), "left": p.expr(expr.Left), "right": p.expr(expr.Right)}
+}
+
+func (p *JSONPrinter) VisitLogicalExpr(expr *exp.Logical) any {
+	return node{"kind": "Logical", "operator": string(expr.Operator.Lexeme), "left": p.expr(expr.Left), "right": p.expr(expr.Right)}
+}
+
+func (p *JSONPrinter) VisitGroupingExpr(expr *exp.Grouping) any {
+	return node{"kind": "Grouping", "expression": p.expr(expr.Expression)}
+}
+
+func (p *JSONPrinter) VisitLiteralExpr(expr *exp.Literal) any {
+	value := expr.Value
+	if runes, ok := value.([]rune); ok {
+		value = string(runes)
+	}
+	return node{"kind": "Literal", "value": value}
+}
+
+func (p *JSONPrinter) VisitUnaryExpr(expr *exp.Unary) any {
+	return node{"kind": "Unary", "operator": string(expr.Operator.Lexeme), "right": p.expr(expr.Right)}
+}
+
+func (p *JSONPrinter) VisitCallExpr(expr *exp.Call) any {
+	return node{"kind": "Call", "callee": p.expr(expr.Callee), "arguments": p.exprs(expr.Arguments)}
+}
+
+func (p *JSONPrinter) VisitVariableExpr(expr *exp.Variable) any {
+	return node{"kind": "Variable", "name": name(expr.Name)}
+}
+
+func (p *JSONPrinter) VisitAssignExpr(expr *exp.Assign) any {
+	return node{"kind": "Assign", "name": name(expr.Name), "value": p.expr(expr.Value)}
+}
+
+func (p *JSONPrinter) VisitGetExpr(expr *exp.Get) any {
+	return node{"kind": "Get", "object": p.expr(expr.Object), "name": name(expr.Name)}
+}
+
+func (p *JSONPrinter) VisitSetExpr(expr *exp.Set) any {
+	return node{"kind": "Set", "object": p.expr(expr.Object), "name": name(expr.Name), "value": p.expr(expr.Value)}
+}
+
+func (p *JSONPrinter) VisitThisExpr(expr *exp.This) any {
+	return node{"kind": "This"}
+}
+
+func (p *JSONPrinter) VisitSuperExpr(expr *exp.Super) any {
+	return node{"kind": "Super", "method": name(expr.Method)}
+}
+
+func (p *JSONPrinter) VisitListExpr(expr *exp.List) any {
+	return node{"kind": "List", "elements": p.exprs(expr.Elements)}
+}
+
+func (p *JSONPrinter) VisitMapExpr(expr *exp.Map) any {
+	return node{"kind": "Map", "keys": p.exprs(expr.Keys), "values": p.exprs(expr.Values)}
+}
+
+func (p *JSONPrinter) VisitIndexExpr(expr *exp.Index) any {
+	return node{"kind": "Index", "object": p.expr(expr.Object), "index": p.expr(expr.Index)}
+}
+
+func (p *JSONPrinter) VisitIndexSetExpr(expr *exp.IndexSet) any {
+	return node{"kind": "IndexSet", "object": p.expr(expr.Object), "index": p.expr(expr.Index), "value": p.expr(expr.Value)}
+}
+
+func (p *JSONPrinter) VisitFunctionExpr(expr *exp.Function) any {
+	declaration := expr.Declaration.(*st.Function)
+	params := make([]any, len(declaration.Params))
+	for i, param := range declaration.Params {
+		params[i] = name(param)
+	}
+	return node{"kind": "Function", "params": params, "body": p.stmts(declaration.Body)}
+}
+
+// Statement visitor
+
+func (p *JSONPrinter) VisitExpressionStmt(stmt *st.Expression) any {
+	return node{"kind": "Expression", "expression": p.expr(stmt.Expression)}
+}
+
+func (p *JSONPrinter) VisitPrintStmt(stmt *st.Print) any {
+	return node{"kind": "Print", "expression": p.expr(stmt.Expression)}
+}
+
+func (p *JSONPrinter) VisitVarStmt(stmt *st.Var) any {
+	return node{"kind": "Var", "name": name(stmt.Name), "initializer": p.expr(stmt.Initializer)}
+}
+
+func (p *JSONPrinter) VisitBlockStmt(stmt *st.Block) any {
+	return node{"kind": "Block", "statements": p.stmts(stmt.Statements)}
+}
+
+func (p *JSONPrinter) VisitIfStmt(stmt *st.If) any {
+	return node{"kind": "If", "condition": p.expr(stmt.Condition), "then": p.stmt(stmt.ThenBranch), "else": p.stmt(stmt.ElseBranch)}
+}
+
+func (p *JSONPrinter) VisitWhileStmt(stmt *st.While) any {
+	return node{"kind": "While", "condition": p.expr(stmt.Condition), "body": p.stmt(stmt.Body), "increment": p.expr(stmt.Increment)}
+}
+
+func (p *JSONPrinter) VisitBreakStmt(stmt *st.Break) any {
+	return node{"kind": "Break"}
+}
+
+func (p *JSONPrinter) VisitContinueStmt(stmt *st.Continue) any {
+	return node{"kind": "Continue"}
+}
+
+func (p *JSONPrinter) VisitFunctionStmt(stmt *st.Function) any {
+	params := make([]any, len(stmt.Params))
+	for i, param := range stmt.Params {
+		params[i] = name(param)
+	}
+	return node{"kind": "Function", "name": name(stmt.Name), "params": params, "body": p.stmts(stmt.Body)}
+}
+
+func (p *JSONPrinter) VisitReturnStmt(stmt *st.Return) any {
+	return node{"kind": "Return", "value": p.expr(stmt.Value)}
+}
+
+func (p *JSONPrinter) VisitThrowStmt(stmt *st.Throw) any {
+	return node{"kind": "Throw", "value": p.expr(stmt.Value)}
+}
+
+func (p *JSONPrinter) VisitTryStmt(stmt *st.Try) any {
+	n := node{"kind": "Try", "body": p.stmt(stmt.Body), "catchName": nil, "catch": nil, "finally": nil}
+	if stmt.Catch != nil {
+		n["catchName"] = name(stmt.CatchName)
+		n["catch"] = p.stmt(stmt.Catch)
+	}
+	if stmt.Finally != nil {
+		n["finally"] = p.stmt(stmt.Finally)
+	}
+	return n
+}
+
+func (p *JSONPrinter) VisitImportStmt(stmt *st.Import) any {
+	n := node{"kind": "Import", "path": string(stmt.Path.Literal.([]rune)), "alias": nil, "names": nil}
+	if len(stmt.Names) == 0 {
+		n["alias"] = name(stmt.Alias)
+		return n
+	}
+	names := make([]any, len(stmt.Names))
+	for i, imported := range stmt.Names {
+		names[i] = name(imported)
+	}
+	n["names"] = names
+	return n
+}
+
+func (p *JSONPrinter) VisitClassStmt(stmt *st.Class) any {
+	var superclass any
+	if stmt.Superclass != nil {
+		superclass = p.expr(stmt.Superclass)
+	}
+	methods := make([]any, len(stmt.Methods))
+	for i, method := range stmt.Methods {
+		methods[i] = p.stmt(method)
+	}
+	return node{"kind": "Class", "name": name(stmt.Name), "superclass": superclass, "methods": methods}
+}
+
+var _ exp.ExprVisitor = (*JSONPrinter)(nil)
+var _ st.StmtVisitor = (*JSONPrinter)(nil)
@@ -2,8 +2,10 @@ package printer
 
 import (
 	"fmt"
+	"strings"
 
 	exp "github.com/codecrafters-io/interpreter-starter-go/app/expr"
+	st "github.com/codecrafters-io/interpreter-starter-go/app/stmt"
 )
 
 type AstPrinter struct {
@@ -17,6 +19,16 @@ func (p *AstPrinter) Print(expr exp.Expr) string {
 	return expr.Accept(p).(string)
 }
 
+// PrintProgram prints each top-level statement on its own line.
+func (p *AstPrinter) PrintProgram(statements []st.Stmt) string {
+	var b strings.Builder
+	for _, statement := range statements {
+		b.WriteString(statement.Accept(p).(string))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
 func (p *AstPrinter) VisitBinaryExpr(expr *exp.Binary) interface{} {
 	return p.parenthesize(string(expr.Operator.Lexeme), expr.Left, expr.Right)
 }
@@ -62,8 +74,7 @@ func (p *AstPrinter) VisitVariableExpr(expr *exp.Variable) interface{} {
 }
 
 func (p *AstPrinter) VisitAssignExpr(expr *exp.Assign) interface{} {
-	// return nil
-	return fmt.Sprintf("%s", string(expr.Name.Lexeme))
+	return p.parenthesize("= "+string(expr.Name.Lexeme), expr.Value)
 }
 
 func (p *AstPrinter) VisitLogicalExpr(expr *exp.Logical) interface{} {
@@ -75,7 +86,164 @@ func (p *AstPrinter) VisitLogicalExpr(expr *exp.Logical) interface{} {
 func (p *AstPrinter) VisitCallExpr(expr *exp.Call) interface{} {
 	// return nil
 	// return p.parenthesizeSlice("call", expr.Callee, expr.Arguments)
-	return p.parenthesizeSlice("call", append(expr.Arguments, expr.Callee))
+	return p.parenthesizeSlice("call", append([]exp.Expr{expr.Callee}, expr.Arguments...))
+}
+
+func (p *AstPrinter) VisitGetExpr(expr *exp.Get) interface{} {
+	return p.parenthesize("."+string(expr.Name.Lexeme), expr.Object)
+}
+
+func (p *AstPrinter) VisitSetExpr(expr *exp.Set) interface{} {
+	return p.parenthesize("="+string(expr.Name.Lexeme), expr.Object, expr.Value)
+}
+
+func (p *AstPrinter) VisitThisExpr(expr *exp.This) interface{} {
+	return "this"
+}
+
+func (p *AstPrinter) VisitSuperExpr(expr *exp.Super) interface{} {
+	return fmt.Sprintf("(super %s)", string(expr.Method.Lexeme))
+}
+
+func (p *AstPrinter) VisitListExpr(expr *exp.List) interface{} {
+	return p.parenthesizeSlice("list", expr.Elements)
+}
+
+func (p *AstPrinter) VisitIndexExpr(expr *exp.Index) interface{} {
+	return p.parenthesize("index", expr.Object, expr.Index)
+}
+
+func (p *AstPrinter) VisitIndexSetExpr(expr *exp.IndexSet) interface{} {
+	return p.parenthesize("index=", expr.Object, expr.Index, expr.Value)
+}
+
+func (p *AstPrinter) VisitMapExpr(expr *exp.Map) interface{} {
+	entries := make([]exp.Expr, 0, 2*len(expr.Keys))
+	for n, key := range expr.Keys {
+		entries = append(entries, key, expr.Values[n])
+	}
+	return p.parenthesizeSlice("map", entries)
+}
+
+// Statement visitor
+
+func (p *AstPrinter) VisitExpressionStmt(stmt *st.Expression) any {
+	return p.parenthesize(";", stmt.Expression)
+}
+
+func (p *AstPrinter) VisitPrintStmt(stmt *st.Print) any {
+	return p.parenthesize("print", stmt.Expression)
+}
+
+func (p *AstPrinter) VisitVarStmt(stmt *st.Var) any {
+	if stmt.Initializer == nil {
+		return fmt.Sprintf("(var %s)", string(stmt.Name.Lexeme))
+	}
+	return p.parenthesize("var "+string(stmt.Name.Lexeme), stmt.Initializer)
+}
+
+func (p *AstPrinter) VisitBlockStmt(stmt *st.Block) any {
+	return p.block("block", stmt.Statements)
+}
+
+func (p *AstPrinter) VisitIfStmt(stmt *st.If) any {
+	result := "(if " + stmt.Condition.Accept(p).(string) + " " + stmt.ThenBranch.Accept(p).(string)
+	if stmt.ElseBranch != nil {
+		result += " " + stmt.ElseBranch.Accept(p).(string)
+	}
+	return result + ")"
+}
+
+func (p *AstPrinter) VisitWhileStmt(stmt *st.While) any {
+	result := "(while " + stmt.Condition.Accept(p).(string) + " " + stmt.Body.Accept(p).(string)
+	if stmt.Increment != nil {
+		result += " " + stmt.Increment.Accept(p).(string)
+	}
+	return result + ")"
+}
+
+func (p *AstPrinter) VisitBreakStmt(stmt *st.Break) any {
+	return "(break)"
+}
+
+func (p *AstPrinter) VisitContinueStmt(stmt *st.Continue) any {
+	return "(continue)"
+}
+
+func (p *AstPrinter) VisitFunctionStmt(stmt *st.Function) any {
+	params := make([]string, len(stmt.Params))
+	for i, param := range stmt.Params {
+		params[i] = string(param.Lexeme)
+	}
+	header := fmt.Sprintf("fun %s (%s)", string(stmt.Name.Lexeme), strings.Join(params, " "))
+	return p.block(header, stmt.Body)
+}
+
+func (p *AstPrinter) VisitReturnStmt(stmt *st.Return) any {
+	if stmt.Value == nil {
+		return "(return)"
+	}
+	return p.parenthesize("return", stmt.Value)
+}
+
+func (p *AstPrinter) VisitThrowStmt(stmt *st.Throw) any {
+	return p.parenthesize("throw", stmt.Value)
+}
+
+func (p *AstPrinter) VisitTryStmt(stmt *st.Try) any {
+	result := "(try " + stmt.Body.Accept(p).(string)
+	if stmt.Catch != nil {
+		result += " " + p.block("catch "+string(stmt.CatchName.Lexeme), stmt.Catch.Statements)
+	}
+	if stmt.Finally != nil {
+		result += " " + p.block("finally", stmt.Finally.Statements)
+	}
+	return result + ")"
+}
+
+func (p *AstPrinter) VisitImportStmt(stmt *st.Import) any {
+	if len(stmt.Names) == 0 {
+		return fmt.Sprintf("(import %s as %s)", string(stmt.Path.Lexeme), string(stmt.Alias.Lexeme))
+	}
+	names := make([]string, len(stmt.Names))
+	for i, name := range stmt.Names {
+		names[i] = string(name.Lexeme)
+	}
+	return fmt.Sprintf("(from %s import %s)", string(stmt.Path.Lexeme), strings.Join(names, " "))
+}
+
+func (p *AstPrinter) VisitClassStmt(stmt *st.Class) any {
+	header := "class " + string(stmt.Name.Lexeme)
+	if stmt.Superclass != nil {
+		header += " < " + string(stmt.Superclass.Name.Lexeme)
+	}
+	methods := make([]st.Stmt, len(stmt.Methods))
+	for i, method := range stmt.Methods {
+		methods[i] = method
+	}
+	return p.block(header, methods)
+}
+
+func (p *AstPrinter) VisitFunctionExpr(expr *exp.Function) any {
+	declaration := expr.Declaration.(*st.Function)
+	params := make([]string, len(declaration.Params))
+	for i, param := range declaration.Params {
+		params[i] = string(param.Lexeme)
+	}
+	return p.block(fmt.Sprintf("fun (%s)", strings.Join(params, " ")), declaration.Body)
+}
+
+func (p *AstPrinter) block(name string, statements []st.Stmt) string {
+	var result string
+	result += "(" + name
+
+	for _, statement := range statements {
+		result += " "
+		result += statement.Accept(p).(string)
+	}
+
+	result += ")"
+	return result
 }
 
 func (p *AstPrinter) parenthesize(name string, exprs ...exp.Expr) string {
@@ -104,3 +272,4 @@ func (p *AstPrinter) parenthesizeSlice(name string, exprs []exp.Expr) string {
 }
 
 var _ exp.ExprVisitor = (*AstPrinter)(nil)
+var _ st.StmtVisitor = (*AstPrinter)(nil)
@@ -0,0 +1,231 @@
+package profile
+
+import (
+	"compress/gzip"
+	"io"
+	"strings"
+)
+
+// Field numbers from pprof's profile.proto.
+const (
+	profileSampleType        = 1
+	profileSample            = 2
+	profileLocation          = 4
+	profileFunction          = 5
+	profileStringTable       = 6
+	profileTimeNanos         = 9
+	profileDurationNanos     = 10
+	profilePeriodType        = 11
+	profilePeriod            = 12
+	profileDefaultSampleType = 14
+
+	valueTypeType = 1
+	valueTypeUnit = 2
+
+	sampleLocationID = 1
+	sampleValue      = 2
+
+	locationID   = 1
+	locationLine = 4
+
+	lineFunctionID = 1
+	lineLine       = 2
+
+	functionID         = 1
+	functionName       = 2
+	functionSystemName = 3
+	functionFilename   = 4
+	functionStartLine  = 5
+)
+
+// pprofName removes angle brackets from names. pprof drops anything
+// between them as C++ template arguments, which would leave <script>
+// without a name.
+var pprofName = strings.NewReplacer("<", "", ">", "")
+
+// WritePprof writes the samples as a gzipped pprof profile, so go tool
+// pprof can show Lox call stacks. Each sample has two values: how many
+// statements ran and how long they took.
+func (p *Profiler) WritePprof(w io.Writer) error {
+	table := newStringTable()
+	var out encoder
+
+	for _, valueType := range [][2]string{{"samples", "count"}, {"time", "nanoseconds"}} {
+		var m encoder
+		m.int64(valueTypeType, table.index(valueType[0]))
+		m.int64(valueTypeUnit, table.index(valueType[1]))
+		out.message(profileSampleType, m)
+	}
+
+	// pprof gives each function a single file, but top-level code runs
+	// the modules it imports too, so each file a function has lines in is
+	// a function of its own.
+	type placed struct {
+		function *Function
+		file     string
+	}
+	functions := make(map[placed]uint64)
+	var order []placed
+
+	// Each line reached in a function is one location.
+	locations := make(map[Location]uint64)
+	var locationMessages encoder
+	for _, s := range p.Samples() {
+		ids := make([]uint64, len(s.Stack))
+		for n, location := range s.Stack {
+			id, ok := locations[location]
+			if !ok {
+				id = uint64(len(locations) + 1)
+				locations[location] = id
+
+				key := placed{location.Function, location.File}
+				if location.Function.Native {
+					key.file = ""
+				}
+				function, ok := functions[key]
+				if !ok {
+					function = uint64(len(functions) + 1)
+					functions[key] = function
+					order = append(order, key)
+				}
+
+				var line encoder
+				line.uint64(lineFunctionID, function)
+				line.int64(lineLine, int64(location.Line))
+				var m encoder
+				m.uint64(locationID, id)
+				m.message(locationLine, line)
+				locationMessages.message(profileLocation, m)
+			}
+			ids[n] = id
+		}
+
+		var m encoder
+		m.packedUint64(sampleLocationID, ids)
+		m.packedInt64(sampleValue, []int64{int64(s.Count), int64(s.Time)})
+		out.message(profileSample, m)
+	}
+	out.bytes = append(out.bytes, locationMessages.bytes...)
+
+	for _, key := range order {
+		f := key.function
+		name := pprofName.Replace(f.Name)
+		var m encoder
+		m.uint64(functionID, functions[key])
+		m.int64(functionName, table.index(name))
+		m.int64(functionSystemName, table.index(name))
+		if key.file != "" {
+			m.int64(functionFilename, table.index(key.file))
+		}
+		// Top-level code starts at the top of every file.
+		if key.file == f.File {
+			m.int64(functionStartLine, int64(f.Line))
+		}
+		out.message(profileFunction, m)
+	}
+
+	out.int64(profileTimeNanos, p.start.UnixNano())
+	out.int64(profileDurationNanos, int64(p.duration))
+	var period encoder
+	period.int64(valueTypeType, table.index("time"))
+	period.int64(valueTypeUnit, table.index("nanoseconds"))
+	out.message(profilePeriodType, period)
+	out.int64(profilePeriod, 1)
+	out.int64(profileDefaultSampleType, table.index("time"))
+
+	// The string table goes last, once every string has been indexed.
+	for _, s := range table.strings {
+		out.string(profileStringTable, s)
+	}
+
+	compressed := gzip.NewWriter(w)
+	if _, e := compressed.Write(out.bytes); e != nil {
+		return e
+	}
+	return compressed.Close()
+}
+
+type stringTable struct {
+	strings []string
+	indices map[string]int64
+}
+
+// newStringTable starts with the empty string, which pprof requires at
+// index 0.
+func newStringTable() *stringTable {
+	return &stringTable{strings: []string{""}, indices: map[string]int64{"": 0}}
+}
+
+func (t *stringTable) index(s string) int64 {
+	if n, ok := t.indices[s]; ok {
+		return n
+	}
+	n := int64(len(t.strings))
+	t.strings = append(t.strings, s)
+	t.indices[s] = n
+	return n
+}
+
+// encoder writes the protocol buffer wire format, just enough of it for
+// profile.proto.
+type encoder struct {
+	bytes []byte
+}
+
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+func (e *encoder) varint(v uint64) {
+	for v >= 0x80 {
+		e.bytes = append(e.bytes, byte(v)|0x80)
+		v >>= 7
+	}
+	e.bytes = append(e.bytes, byte(v))
+}
+
+func (e *encoder) key(field int, wire int) {
+	e.varint(uint64(field)<<3 | uint64(wire))
+}
+
+// Zero values are left out, as proto3 does.
+func (e *encoder) uint64(field int, v uint64) {
+	if v == 0 {
+		return
+	}
+	e.key(field, wireVarint)
+	e.varint(v)
+}
+
+func (e *encoder) int64(field int, v int64) {
+	e.uint64(field, uint64(v))
+}
+
+func (e *encoder) string(field int, s string) {
+	e.key(field, wireBytes)
+	e.varint(uint64(len(s)))
+	e.bytes = append(e.bytes, s...)
+}
+
+func (e *encoder) message(field int, m encoder) {
+	e.key(field, wireBytes)
+	e.varint(uint64(len(m.bytes)))
+	e.bytes = append(e.bytes, m.bytes...)
+}
+
+func (e *encoder) packedUint64(field int, values []uint64) {
+	var packed encoder
+	for _, v := range values {
+		packed.varint(v)
+	}
+	e.message(field, packed)
+}
+
+func (e *encoder) packedInt64(field int, values []int64) {
+	var packed encoder
+	for _, v := range values {
+		packed.varint(uint64(v))
+	}
+	e.message(field, packed)
+}
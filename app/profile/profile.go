@@ -0,0 +1,355 @@
+// Package profile measures where a Lox program spends its time. The
+// interpreter reports every call, return and statement to a Profiler,
+// which keeps totals for each function and samples for each line of the
+// call stack. The result can be printed as a table or written as a pprof
+// profile.
+package profile
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Function totals the calls made to one Lox function or native.
+type Function struct {
+	Name string
+	// File and Line are where the function is declared. Line is 0 for
+	// natives and top-level code, and File is empty for natives.
+	File   string
+	Line   int
+	Native bool
+	Calls  int
+	// Inclusive is the time between entering and leaving the function;
+	// Exclusive leaves out the time spent in the functions it called.
+	Inclusive time.Duration
+	Exclusive time.Duration
+	// Samples counts the statements executed directly in the function.
+	Samples int
+
+	id int
+	// active is how many calls are on the stack, so a recursive function
+	// counts its inclusive time once.
+	active int
+}
+
+func (f *Function) String() string {
+	if f.Native {
+		return f.Name + " (native)"
+	}
+	if f.Line == 0 {
+		return f.Name
+	}
+	return fmt.Sprintf("%s (line %d)", f.Name, f.Line)
+}
+
+// Location is a line reached in a function. The file is the function's
+// own except for top-level code, which also runs the modules it imports.
+type Location struct {
+	Function *Function
+	File     string
+	Line     int
+}
+
+// Sample is every statement executed with the same call stack. Time is
+// how long the program spent there.
+type Sample struct {
+	// Stack is innermost first.
+	Stack []Location
+	Count int
+	Time  time.Duration
+}
+
+type frame struct {
+	function *Function
+	file     string
+	line     int
+	start    time.Time
+}
+
+type functionKey struct {
+	name   string
+	file   string
+	line   int
+	native bool
+}
+
+// Profiler is not safe for concurrent use. Stop it once the program has
+// finished, before reading the results.
+type Profiler struct {
+	// Filename is the script being profiled, as named in the table.
+	Filename string
+
+	// script is the absolute path of Filename, which the interpreter
+	// reports files by.
+	script string
+	start  time.Time
+	// last is when time was last charged to the current sample.
+	last      time.Time
+	duration  time.Duration
+	functions map[functionKey]*Function
+	order     []*Function
+	stack     []frame
+	samples   map[string]*Sample
+	current   *Sample
+	stopped   bool
+}
+
+// New starts profiling, with top-level code as the outermost frame.
+func New(filename string) *Profiler {
+	now := time.Now()
+	script, _ := filepath.Abs(filename)
+	p := &Profiler{
+		Filename:  filename,
+		script:    script,
+		start:     now,
+		last:      now,
+		functions: make(map[functionKey]*Function),
+		samples:   make(map[string]*Sample),
+	}
+	p.push(p.function("<script>", script, 0, false), now)
+	return p
+}
+
+func (p *Profiler) function(name string, file string, line int, native bool) *Function {
+	key := functionKey{name, file, line, native}
+	f, ok := p.functions[key]
+	if !ok {
+		f = &Function{Name: name, File: file, Line: line, Native: native, id: len(p.order) + 1}
+		p.functions[key] = f
+		p.order = append(p.order, f)
+	}
+	return f
+}
+
+// charge gives the time since the last event to the current sample and
+// the innermost function.
+func (p *Profiler) charge(now time.Time) {
+	elapsed := now.Sub(p.last)
+	p.last = now
+	p.stack[len(p.stack)-1].function.Exclusive += elapsed
+	if p.current != nil {
+		p.current.Time += elapsed
+	}
+}
+
+func (p *Profiler) push(f *Function, now time.Time) {
+	f.Calls++
+	f.active++
+	// Until its first statement runs, a function is at its declaration.
+	p.stack = append(p.stack, frame{function: f, file: f.File, line: f.Line, start: now})
+	p.current = p.sample()
+}
+
+// sample finds the sample for the current call stack.
+func (p *Profiler) sample() *Sample {
+	var key strings.Builder
+	for n := len(p.stack) - 1; n >= 0; n-- {
+		key.WriteString(strconv.Itoa(p.stack[n].function.id))
+		key.WriteByte(':')
+		key.WriteString(p.stack[n].file)
+		key.WriteByte(':')
+		key.WriteString(strconv.Itoa(p.stack[n].line))
+		key.WriteByte(';')
+	}
+	s, ok := p.samples[key.String()]
+	if !ok {
+		s = &Sample{Stack: make([]Location, 0, len(p.stack))}
+		for n := len(p.stack) - 1; n >= 0; n-- {
+			s.Stack = append(s.Stack, Location{p.stack[n].function, p.stack[n].file, p.stack[n].line})
+		}
+		p.samples[key.String()] = s
+	}
+	return s
+}
+
+// Enter records a call to the function called name, declared on line of
+// file.
+func (p *Profiler) Enter(name string, file string, line int, native bool) {
+	if p.stopped {
+		return
+	}
+	now := time.Now()
+	p.charge(now)
+	p.push(p.function(name, file, line, native), now)
+}
+
+// Exit records the innermost function returning.
+func (p *Profiler) Exit() {
+	// Top-level code only leaves when the profiler stops.
+	if p.stopped || len(p.stack) == 1 {
+		return
+	}
+	p.pop(time.Now())
+	p.current = p.sample()
+}
+
+func (p *Profiler) pop(now time.Time) {
+	p.charge(now)
+	top := p.stack[len(p.stack)-1]
+	p.stack = p.stack[:len(p.stack)-1]
+	top.function.active--
+	if top.function.active == 0 {
+		top.function.Inclusive += now.Sub(top.start)
+	}
+}
+
+// Line records a statement on line of file being executed by the
+// innermost function.
+func (p *Profiler) Line(file string, line int) {
+	if p.stopped {
+		return
+	}
+	p.charge(time.Now())
+	top := &p.stack[len(p.stack)-1]
+	top.file, top.line = file, line
+	top.function.Samples++
+	p.current = p.sample()
+	p.current.Count++
+}
+
+// Stop ends profiling, closing any calls a runtime error left open.
+func (p *Profiler) Stop() {
+	if p.stopped {
+		return
+	}
+	now := time.Now()
+	for len(p.stack) > 0 {
+		p.pop(now)
+	}
+	p.duration = now.Sub(p.start)
+	p.stopped = true
+}
+
+// Functions returns every function called, the most exclusive time first.
+func (p *Profiler) Functions() []*Function {
+	functions := append([]*Function(nil), p.order...)
+	sort.SliceStable(functions, func(a, b int) bool {
+		return functions[a].Exclusive > functions[b].Exclusive
+	})
+	return functions
+}
+
+// Samples returns the samples ordered by call stack, outermost frame
+// first.
+func (p *Profiler) Samples() []*Sample {
+	samples := make([]*Sample, 0, len(p.samples))
+	for _, s := range p.samples {
+		samples = append(samples, s)
+	}
+	sort.Slice(samples, func(a, b int) bool {
+		return stackKey(samples[a].Stack) < stackKey(samples[b].Stack)
+	})
+	return samples
+}
+
+func stackKey(stack []Location) string {
+	var key strings.Builder
+	for n := len(stack) - 1; n >= 0; n-- {
+		fmt.Fprintf(&key, "%08d:%s:%08d;", stack[n].Function.id, stack[n].File, stack[n].Line)
+	}
+	return key.String()
+}
+
+// LineTotal adds up the samples taken on one line, whatever called it.
+type LineTotal struct {
+	File  string
+	Line  int
+	Count int
+	Time  time.Duration
+}
+
+// Lines returns a total for each line of the script and the modules it
+// imports, the most samples first. Time spent in natives counts towards
+// the line that called them.
+func (p *Profiler) Lines() []LineTotal {
+	totals := make(map[Location]*LineTotal)
+	for _, s := range p.samples {
+		var at Location
+		for _, location := range s.Stack {
+			if !location.Function.Native {
+				at = Location{File: location.File, Line: location.Line}
+				break
+			}
+		}
+		if at.Line == 0 {
+			continue
+		}
+		total, ok := totals[at]
+		if !ok {
+			total = &LineTotal{File: at.File, Line: at.Line}
+			totals[at] = total
+		}
+		total.Count += s.Count
+		total.Time += s.Time
+	}
+	lines := make([]LineTotal, 0, len(totals))
+	for _, total := range totals {
+		lines = append(lines, *total)
+	}
+	sort.Slice(lines, func(a, b int) bool {
+		if lines[a].Count != lines[b].Count {
+			return lines[a].Count > lines[b].Count
+		}
+		if lines[a].File != lines[b].File {
+			return lines[a].File < lines[b].File
+		}
+		return lines[a].Line < lines[b].Line
+	})
+	return lines
+}
+
+// maxTableLines caps the hot lines WriteTable prints.
+const maxTableLines = 10
+
+// WriteTable prints the functions, then the busiest lines.
+func (p *Profiler) WriteTable(w io.Writer) {
+	fmt.Fprintf(w, "Profile of %s, %s\n\n", p.Filename, milliseconds(p.duration))
+	fmt.Fprintf(w, "%-30s %8s %12s %12s %8s\n", "Function", "Calls", "Inclusive", "Exclusive", "Samples")
+	for _, f := range p.Functions() {
+		name := f.String()
+		if where := p.where(f.File); where != "" && f.Line != 0 {
+			name = fmt.Sprintf("%s (%s:%d)", f.Name, where, f.Line)
+		}
+		fmt.Fprintf(w, "%-30s %8d %12s %12s %8d\n", name, f.Calls, milliseconds(f.Inclusive), milliseconds(f.Exclusive), f.Samples)
+	}
+
+	totals := p.Lines()
+	if len(totals) > maxTableLines {
+		totals = totals[:maxTableLines]
+	}
+	// Lines in imported modules are named with their file.
+	labels := make([]string, len(totals))
+	width := 8
+	for n, total := range totals {
+		labels[n] = strconv.Itoa(total.Line)
+		if where := p.where(total.File); where != "" {
+			labels[n] = where + ":" + labels[n]
+		}
+		width = max(width, len(labels[n]))
+	}
+	fmt.Fprintf(w, "\n%-*s %8s %12s\n", width, "Line", "Samples", "Time")
+	for n, total := range totals {
+		fmt.Fprintf(w, "%-*s %8d %12s\n", width, labels[n], total.Count, milliseconds(total.Time))
+	}
+}
+
+// where names file for the table relative to the script, or returns ""
+// for the script itself, whose lines need no file.
+func (p *Profiler) where(file string) string {
+	if file == "" || file == p.script {
+		return ""
+	}
+	if rel, e := filepath.Rel(filepath.Dir(p.script), file); e == nil {
+		return rel
+	}
+	return file
+}
+
+func milliseconds(d time.Duration) string {
+	return fmt.Sprintf("%.3fms", float64(d)/float64(time.Millisecond))
+}
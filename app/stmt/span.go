@@ -0,0 +1,66 @@
+package stmt
+
+import (
+	expr "github.com/codecrafters-io/interpreter-starter-go/app/expr"
+	token "github.com/codecrafters-io/interpreter-starter-go/app/token"
+)
+
+// Span returns the source range covered by a statement. It runs from the
+// statement's first token to the end of its last expression or nested
+// statement, so closing semicolons and braces are not included.
+func Span(s Stmt) token.Span {
+	switch s := s.(type) {
+	case *Expression:
+		return expr.Span(s.Expression)
+	case *Print:
+		return s.Keyword.Span().Join(expr.Span(s.Expression))
+	case *Var:
+		return s.Keyword.Span().Join(s.Name.Span()).Join(expr.Span(s.Initializer))
+	case *Block:
+		return s.Brace.Span().Join(last(s.Statements))
+	case *If:
+		return s.Keyword.Span().Join(Span(s.ThenBranch)).Join(Span(s.ElseBranch))
+	case *While:
+		return s.Keyword.Span().Join(Span(s.Body))
+	case *Class:
+		span := s.Name.Span()
+		if len(s.Methods) > 0 {
+			span = span.Join(Span(s.Methods[len(s.Methods)-1]))
+		}
+		return span
+	case *Function:
+		return s.Name.Span().Join(last(s.Body))
+	case *Return:
+		return s.Keyword.Span().Join(expr.Span(s.Value))
+	case *Break:
+		return s.Keyword.Span()
+	case *Continue:
+		return s.Keyword.Span()
+	case *Throw:
+		return s.Keyword.Span().Join(expr.Span(s.Value))
+	case *Import:
+		span := s.Keyword.Span().Join(s.Path.Span())
+		if len(s.Names) > 0 {
+			return span.Join(s.Names[len(s.Names)-1].Span())
+		}
+		return span.Join(s.Alias.Span())
+	case *Try:
+		span := s.Keyword.Span().Join(Span(s.Body))
+		if s.Catch != nil {
+			span = span.Join(Span(s.Catch))
+		}
+		if s.Finally != nil {
+			span = span.Join(Span(s.Finally))
+		}
+		return span
+	default:
+		return token.Span{}
+	}
+}
+
+func last(statements []Stmt) token.Span {
+	if len(statements) == 0 {
+		return token.Span{}
+	}
+	return Span(statements[len(statements)-1])
+}
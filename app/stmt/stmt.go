@@ -15,9 +15,14 @@ type StmtVisitor interface {
 	VisitIfStmt(stmt *If) any
 	VisitWhileStmt(stmt *While) any
 	// VisitForStmt(stmt *For) any
-	// VisitClassStmt(stmt *Class) any
+	VisitClassStmt(stmt *Class) any
 	VisitFunctionStmt(stmt *Function) any
 	VisitReturnStmt(stmt *Return) interface{}
+	VisitThrowStmt(stmt *Throw) any
+	VisitTryStmt(stmt *Try) any
+	VisitBreakStmt(stmt *Break) any
+	VisitContinueStmt(stmt *Continue) any
+	VisitImportStmt(stmt *Import) any
 }
 
 type Stmt interface {
@@ -35,6 +40,7 @@ func (e *Expression) Accept(visitor StmtVisitor) any {
 var _ Stmt = &Expression{}
 
 type Print struct {
+	Keyword    token.Token
 	Expression expr.Expr
 }
 
@@ -45,6 +51,7 @@ func (p *Print) Accept(visitor StmtVisitor) any {
 var _ Stmt = &Print{}
 
 type Var struct {
+	Keyword     token.Token
 	Name        token.Token
 	Initializer expr.Expr
 }
@@ -77,7 +84,10 @@ var _ Stmt = &Var{}
 // var _ Stmt = &Variable{}
 
 type Block struct {
+	Brace      token.Token
 	Statements []Stmt
+	// End is the closing brace.
+	End token.Token
 }
 
 func (b *Block) Accept(visitor StmtVisitor) any {
@@ -87,6 +97,7 @@ func (b *Block) Accept(visitor StmtVisitor) any {
 var _ Stmt = &Block{}
 
 type If struct {
+	Keyword    token.Token
 	Condition  expr.Expr
 	ThenBranch Stmt
 	ElseBranch Stmt
@@ -98,21 +109,28 @@ func (i *If) Accept(visitor StmtVisitor) any {
 
 var _ Stmt = &If{}
 
-// type Class struct {
-// 	Name       Token
-// 	SuperClass *Var
-// 	Methods    []*Function
-// }
+type Class struct {
+	Name       token.Token
+	Superclass *expr.Variable
+	Methods    []*Function
+	// End is the closing brace.
+	End token.Token
+}
 
-// func (c *Class) Accept(visitor StmtVisitor) interface{} {
-// 	return visitor.VisitClassStmt(c)
-// }
+func (c *Class) Accept(visitor StmtVisitor) any {
+	return visitor.VisitClassStmt(c)
+}
 
-// var _ Stmt = &Class{}
+var _ Stmt = &Class{}
 
+// While is also what a for loop becomes, with Keyword the for keyword.
+// Increment is only set for a for loop, and runs after each pass through
+// the body, including one cut short by continue.
 type While struct {
+	Keyword   token.Token
 	Condition expr.Expr
 	Body      Stmt
+	Increment expr.Expr
 }
 
 func (w *While) Accept(visitor StmtVisitor) any {
@@ -125,6 +143,8 @@ type Function struct {
 	Name   token.Token
 	Params []token.Token
 	Body   []Stmt
+	// End is the closing brace.
+	End token.Token
 }
 
 func (f *Function) Accept(visitor StmtVisitor) any {
@@ -143,3 +163,66 @@ func (r *Return) Accept(visitor StmtVisitor) interface{} {
 }
 
 var _ Stmt = &Return{}
+
+type Throw struct {
+	Keyword token.Token
+	Value   expr.Expr
+}
+
+func (t *Throw) Accept(visitor StmtVisitor) any {
+	return visitor.VisitThrowStmt(t)
+}
+
+var _ Stmt = &Throw{}
+
+// Try has a Catch, a Finally or both. CatchName is bound to the error in
+// the same scope as the catch block's statements.
+type Try struct {
+	Keyword   token.Token
+	Body      *Block
+	CatchName token.Token
+	Catch     *Block
+	Finally   *Block
+}
+
+func (t *Try) Accept(visitor StmtVisitor) any {
+	return visitor.VisitTryStmt(t)
+}
+
+var _ Stmt = &Try{}
+
+type Break struct {
+	Keyword token.Token
+}
+
+func (b *Break) Accept(visitor StmtVisitor) any {
+	return visitor.VisitBreakStmt(b)
+}
+
+var _ Stmt = &Break{}
+
+type Continue struct {
+	Keyword token.Token
+}
+
+func (c *Continue) Accept(visitor StmtVisitor) any {
+	return visitor.VisitContinueStmt(c)
+}
+
+var _ Stmt = &Continue{}
+
+// Import is "import Path as Alias;" or "from Path import Names;", with
+// Keyword the import or from keyword. Alias is the zero token in the
+// second form.
+type Import struct {
+	Keyword token.Token
+	Path    token.Token
+	Alias   token.Token
+	Names   []token.Token
+}
+
+func (i *Import) Accept(visitor StmtVisitor) any {
+	return visitor.VisitImportStmt(i)
+}
+
+var _ Stmt = &Import{}
@@ -0,0 +1,238 @@
+// Package text implements the built-in string methods shared by the
+// tree-walking interpreter and the bytecode VM. Strings are []rune, the way
+// the scanner produces them, so indexes and lengths count characters
+// rather than bytes. The VM converts its strings on the way in and out.
+package text
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/codecrafters-io/interpreter-starter-go/app/collection"
+)
+
+// Method returns the built-in method name bound to s.
+func Method(s []rune, name string) (collection.Method, bool) {
+	switch name {
+	case "substr":
+		return collection.Method{Name: name, Arity: 2, Fn: substr(s)}, true
+	case "indexOf":
+		return collection.Method{Name: name, Arity: 1, Fn: indexOf(s)}, true
+	case "split":
+		return collection.Method{Name: name, Arity: 1, Fn: split(s)}, true
+	case "trim":
+		return collection.Method{Name: name, Arity: 0, Fn: trim(s)}, true
+	case "upper":
+		return collection.Method{Name: name, Arity: 0, Fn: upper(s)}, true
+	case "lower":
+		return collection.Method{Name: name, Arity: 0, Fn: lower(s)}, true
+	case "replace":
+		return collection.Method{Name: name, Arity: 2, Fn: replace(s)}, true
+	case "startsWith":
+		return collection.Method{Name: name, Arity: 1, Fn: startsWith(s)}, true
+	case "endsWith":
+		return collection.Method{Name: name, Arity: 1, Fn: endsWith(s)}, true
+	case "repeat":
+		return collection.Method{Name: name, Arity: 1, Fn: repeat(s)}, true
+	case "charCode":
+		return collection.Method{Name: name, Arity: 1, Fn: charCode(s)}, true
+	}
+	return collection.Method{}, false
+}
+
+// Index returns the character at i as a string of its own.
+func Index(s []rune, i any) ([]rune, error) {
+	n, e := index(i, len(s))
+	if e != nil {
+		return nil, e
+	}
+	return []rune{s[n]}, nil
+}
+
+// FromCharCode is the fromCharCode native: the one-character string with
+// the given code point.
+func FromCharCode(args []any) (any, error) {
+	code, ok := args[0].(float64)
+	if !ok || code != math.Trunc(code) || code < 0 || code > unicode.MaxRune || !utf8.ValidRune(rune(code)) {
+		return nil, errors.New("Character code must be a valid code point.")
+	}
+	return []rune{rune(code)}, nil
+}
+
+// index checks that i is an integer number in [0, limit).
+func index(i any, limit int) (int, error) {
+	n, ok := i.(float64)
+	if !ok {
+		return 0, errors.New("String index must be a number.")
+	}
+	if n != math.Trunc(n) {
+		return 0, errors.New("String index must be an integer.")
+	}
+	if n < 0 || n >= float64(limit) {
+		return 0, errors.New("String index out of range.")
+	}
+	return int(n), nil
+}
+
+// count checks that n is a non-negative integer number.
+func count(n any, what string) (int, error) {
+	f, ok := n.(float64)
+	if !ok || f != math.Trunc(f) || f < 0 || f > math.MaxInt32 {
+		return 0, errors.New(what + " must be a non-negative integer.")
+	}
+	return int(f), nil
+}
+
+func argument(value any) ([]rune, error) {
+	s, ok := value.([]rune)
+	if !ok {
+		return nil, errors.New("Argument must be a string.")
+	}
+	return s, nil
+}
+
+// substr returns length characters from start on, or as many as there
+// are.
+func substr(s []rune) func([]any) (any, error) {
+	return func(args []any) (any, error) {
+		// Starting at len(s) gives the empty string.
+		start, e := index(args[0], len(s)+1)
+		if e != nil {
+			return nil, e
+		}
+		length, e := count(args[1], "Substring length")
+		if e != nil {
+			return nil, e
+		}
+		end := min(start+length, len(s))
+
+		result := make([]rune, end-start)
+		copy(result, s[start:end])
+		return result, nil
+	}
+}
+
+// indexOf returns the index of the first occurrence of a string, or -1.
+func indexOf(s []rune) func([]any) (any, error) {
+	return func(args []any) (any, error) {
+		sub, e := argument(args[0])
+		if e != nil {
+			return nil, e
+		}
+		for n := 0; n+len(sub) <= len(s); n++ {
+			if equal(s[n:n+len(sub)], sub) {
+				return float64(n), nil
+			}
+		}
+		return float64(-1), nil
+	}
+}
+
+// split returns the parts between occurrences of a separator. The empty
+// separator splits s into its characters.
+func split(s []rune) func([]any) (any, error) {
+	return func(args []any) (any, error) {
+		sep, e := argument(args[0])
+		if e != nil {
+			return nil, e
+		}
+		parts := strings.Split(string(s), string(sep))
+		elements := make([]any, len(parts))
+		for n, part := range parts {
+			elements[n] = []rune(part)
+		}
+		return collection.NewList(elements), nil
+	}
+}
+
+func trim(s []rune) func([]any) (any, error) {
+	return func(args []any) (any, error) {
+		return []rune(strings.TrimSpace(string(s))), nil
+	}
+}
+
+func upper(s []rune) func([]any) (any, error) {
+	return func(args []any) (any, error) {
+		return []rune(strings.ToUpper(string(s))), nil
+	}
+}
+
+func lower(s []rune) func([]any) (any, error) {
+	return func(args []any) (any, error) {
+		return []rune(strings.ToLower(string(s))), nil
+	}
+}
+
+// replace replaces every occurrence of a string.
+func replace(s []rune) func([]any) (any, error) {
+	return func(args []any) (any, error) {
+		old, e := argument(args[0])
+		if e != nil {
+			return nil, e
+		}
+		replacement, e := argument(args[1])
+		if e != nil {
+			return nil, e
+		}
+		return []rune(strings.ReplaceAll(string(s), string(old), string(replacement))), nil
+	}
+}
+
+func startsWith(s []rune) func([]any) (any, error) {
+	return func(args []any) (any, error) {
+		prefix, e := argument(args[0])
+		if e != nil {
+			return nil, e
+		}
+		return len(prefix) <= len(s) && equal(s[:len(prefix)], prefix), nil
+	}
+}
+
+func endsWith(s []rune) func([]any) (any, error) {
+	return func(args []any) (any, error) {
+		suffix, e := argument(args[0])
+		if e != nil {
+			return nil, e
+		}
+		return len(suffix) <= len(s) && equal(s[len(s)-len(suffix):], suffix), nil
+	}
+}
+
+func repeat(s []rune) func([]any) (any, error) {
+	return func(args []any) (any, error) {
+		times, e := count(args[0], "Repeat count")
+		if e != nil {
+			return nil, e
+		}
+		if times > 0 && len(s) > math.MaxInt32/times {
+			return nil, errors.New("Repeated string is too long.")
+		}
+		return []rune(strings.Repeat(string(s), times)), nil
+	}
+}
+
+// charCode returns the code point of the character at an index.
+func charCode(s []rune) func([]any) (any, error) {
+	return func(args []any) (any, error) {
+		n, e := index(args[0], len(s))
+		if e != nil {
+			return nil, e
+		}
+		return float64(s[n]), nil
+	}
+}
+
+func equal(a, b []rune) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for n := range a {
+		if a[n] != b[n] {
+			return false
+		}
+	}
+	return true
+}
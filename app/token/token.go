@@ -12,7 +12,10 @@ const (
 	RIGHT_PAREN
 	LEFT_BRACE
 	RIGHT_BRACE
+	LEFT_BRACKET
+	RIGHT_BRACKET
 	COMMA
+	COLON
 	DOT
 	MINUS
 	PLUS
@@ -25,6 +28,7 @@ const (
 	BANG_EQUAL
 	EQUAL
 	EQUAL_EQUAL
+	ARROW
 	GREATER
 	GREATER_EQUAL
 	LESS
@@ -37,42 +41,60 @@ const (
 
 	// Keywords.
 	AND
+	BREAK
+	CATCH
 	CLASS
+	CONTINUE
 	ELSE
 	FALSE
+	FINALLY
 	FUN
 	FOR
 	IF
+	IMPORT
 	NIL
 	OR
 	PRINT
 	RETURN
 	SUPER
 	THIS
+	THROW
 	TRUE
+	TRY
 	VAR
 	WHILE
 
+	// COMMENT tokens are kept aside by the scanner for the formatter and
+	// never reach the parser.
+	COMMENT
+
 	EOF
 )
 
 var Keywords = map[string]TokenType{
-	"and":    AND,
-	"class":  CLASS,
-	"else":   ELSE,
-	"false":  FALSE,
-	"for":    FOR,
-	"fun":    FUN,
-	"if":     IF,
-	"nil":    NIL,
-	"or":     OR,
-	"print":  PRINT,
-	"return": RETURN,
-	"super":  SUPER,
-	"this":   THIS,
-	"true":   TRUE,
-	"var":    VAR,
-	"while":  WHILE,
+	"and":      AND,
+	"break":    BREAK,
+	"catch":    CATCH,
+	"class":    CLASS,
+	"continue": CONTINUE,
+	"else":     ELSE,
+	"false":    FALSE,
+	"finally":  FINALLY,
+	"for":      FOR,
+	"fun":      FUN,
+	"if":       IF,
+	"import":   IMPORT,
+	"nil":      NIL,
+	"or":       OR,
+	"print":    PRINT,
+	"return":   RETURN,
+	"super":    SUPER,
+	"this":     THIS,
+	"throw":    THROW,
+	"true":     TRUE,
+	"try":      TRY,
+	"var":      VAR,
+	"while":    WHILE,
 }
 
 func (t TokenType) String() string {
@@ -85,8 +107,14 @@ func (t TokenType) String() string {
 		return "LEFT_BRACE"
 	case RIGHT_BRACE:
 		return "RIGHT_BRACE"
+	case LEFT_BRACKET:
+		return "LEFT_BRACKET"
+	case RIGHT_BRACKET:
+		return "RIGHT_BRACKET"
 	case COMMA:
 		return "COMMA"
+	case COLON:
+		return "COLON"
 	case DOT:
 		return "DOT"
 	case MINUS:
@@ -107,6 +135,8 @@ func (t TokenType) String() string {
 		return "EQUAL"
 	case EQUAL_EQUAL:
 		return "EQUAL_EQUAL"
+	case ARROW:
+		return "ARROW"
 	case GREATER:
 		return "GREATER"
 	case GREATER_EQUAL:
@@ -123,18 +153,28 @@ func (t TokenType) String() string {
 		return "NUMBER"
 	case AND:
 		return "AND"
+	case BREAK:
+		return "BREAK"
+	case CATCH:
+		return "CATCH"
 	case CLASS:
 		return "CLASS"
+	case CONTINUE:
+		return "CONTINUE"
 	case ELSE:
 		return "ELSE"
 	case FALSE:
 		return "FALSE"
+	case FINALLY:
+		return "FINALLY"
 	case FUN:
 		return "FUN"
 	case FOR:
 		return "FOR"
 	case IF:
 		return "IF"
+	case IMPORT:
+		return "IMPORT"
 	case NIL:
 		return "NIL"
 	case OR:
@@ -147,12 +187,18 @@ func (t TokenType) String() string {
 		return "SUPER"
 	case THIS:
 		return "THIS"
+	case THROW:
+		return "THROW"
 	case TRUE:
 		return "TRUE"
+	case TRY:
+		return "TRY"
 	case VAR:
 		return "VAR"
 	case WHILE:
 		return "WHILE"
+	case COMMENT:
+		return "COMMENT"
 	case EOF:
 		return "EOF"
 	default:
@@ -165,6 +211,12 @@ type Token struct {
 	Lexeme  []rune
 	Literal any
 	Line    int
+	// Column is the 1-based position of the token's first character on
+	// its line, counted in characters. Offset and Length locate the lexeme
+	// in the source in bytes. All three are zero for synthetic tokens.
+	Column int
+	Offset int
+	Length int
 }
 
 func NewToken(t TokenType, lexeme []rune, literal any, line int) Token {
@@ -175,6 +227,25 @@ func NewToken(t TokenType, lexeme []rune, literal any, line int) Token {
 		Line:    line,
 	}
 }
+
+// Span returns the source range the token was scanned from. Line is the
+// line the token ends on, like the rest of the interpreter reports, so for
+// multi-line strings the span starts on an earlier line.
+func (t Token) Span() Span {
+	line := t.Line
+	for _, r := range t.Lexeme {
+		if r == '\n' {
+			line--
+		}
+	}
+	return Span{
+		Line:   line,
+		Column: t.Column,
+		Offset: t.Offset,
+		Length: t.Length,
+	}
+}
+
 func (t Token) String() string {
 	var literalStr string
 
@@ -208,3 +279,44 @@ func (t Token) String() string {
 
 	return fmt.Sprintf("%s %s %s\n", t.Type, string(t.Lexeme), literalStr)
 }
+
+// Span locates a range of source text. Line and Column are 1-based and
+// describe where the range starts; Offset and Length are in bytes.
+type Span struct {
+	Line   int
+	Column int
+	Offset int
+	Length int
+}
+
+// End returns the byte offset just past the span.
+func (s Span) End() int {
+	return s.Offset + s.Length
+}
+
+// IsValid reports whether the span points into the source. Spans of
+// synthetic tokens only know their line.
+func (s Span) IsValid() bool {
+	return s.Column > 0
+}
+
+// Join returns the smallest span covering both s and other. Invalid spans
+// are ignored.
+func (s Span) Join(other Span) Span {
+	switch {
+	case !s.IsValid():
+		return other
+	case !other.IsValid():
+		return s
+	}
+
+	start, end := s, other
+	if other.Offset < s.Offset {
+		start = other
+	}
+	if s.End() > other.End() {
+		end = s
+	}
+	start.Length = end.End() - start.Offset
+	return start
+}
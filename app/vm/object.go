@@ -0,0 +1,133 @@
+package vm
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/codecrafters-io/interpreter-starter-go/app/compiler"
+	err "github.com/codecrafters-io/interpreter-starter-go/app/err"
+)
+
+type Closure struct {
+	Function *compiler.Function
+	Upvalues []*Upvalue
+	// Module holds the globals the closure's code reads and writes.
+	Module *Module
+}
+
+func (c *Closure) String() string {
+	return c.Function.String()
+}
+
+// Upvalue points at a stack slot while the variable is still live and owns
+// the value once that slot has been popped.
+type Upvalue struct {
+	location int
+	closed   any
+	next     *Upvalue
+}
+
+type NativeFn func(args []any) (any, error)
+
+// Native is a Go function callable from Lox. A negative Arity accepts any
+// number of arguments.
+type Native struct {
+	Name  string
+	Arity int
+	Fn    NativeFn
+}
+
+func (n *Native) String() string {
+	return "<native fn>"
+}
+
+type Class struct {
+	Name    string
+	Methods map[string]*Closure
+}
+
+func (c *Class) String() string {
+	return c.Name
+}
+
+type Instance struct {
+	Class  *Class
+	Fields map[string]any
+}
+
+func (i *Instance) String() string {
+	return fmt.Sprintf("%s instance", i.Class.Name)
+}
+
+// errorClass is the class of the values runtime errors are caught as.
+var errorClass = &Class{Name: "Error", Methods: make(map[string]*Closure)}
+
+// errorValue is what a catch clause binds: the thrown value, or an Error
+// instance with the message and line of an error the VM raised.
+func errorValue(e *err.RuntimeError) any {
+	if value, ok := e.Thrown(); ok {
+		return value
+	}
+	return &Instance{Class: errorClass, Fields: map[string]any{
+		"message": e.Message(),
+		"line":    float64(e.Line()),
+	}}
+}
+
+// thrownMessage describes a thrown value for when nothing catches it.
+func thrownMessage(value any) string {
+	if instance, ok := value.(*Instance); ok {
+		if message, ok := instance.Fields["message"]; ok {
+			return stringify(message)
+		}
+	}
+	return stringify(value)
+}
+
+type BoundMethod struct {
+	Receiver any
+	Method   *Closure
+}
+
+func (b *BoundMethod) String() string {
+	return b.Method.String()
+}
+
+// Module is the globals of one file, and the namespace value an import of
+// the file binds. Names starting with an underscore are not exported.
+type Module struct {
+	Name    string
+	File    string
+	Globals map[string]any
+	// builtins are the natives the module started out with, which it does
+	// not export.
+	builtins map[string]any
+	// script runs the module's top-level code.
+	script *Closure
+}
+
+// Export returns the value of an exported name.
+func (m *Module) Export(name string) (any, bool) {
+	if strings.HasPrefix(name, "_") {
+		return nil, false
+	}
+	value, ok := m.Globals[name]
+	if builtin, isBuiltin := m.builtins[name]; !ok || (isBuiltin && value == builtin) {
+		return nil, false
+	}
+	return value, true
+}
+
+func (m *Module) String() string {
+	return fmt.Sprintf("<module %s>", m.Name)
+}
+
+// importCycle describes a cycle of imports, outermost module first.
+func importCycle(files []string) string {
+	names := make([]string, len(files))
+	for n, file := range files {
+		names[n] = filepath.Base(file)
+	}
+	return fmt.Sprintf("Import cycle: %s.", strings.Join(names, " -> "))
+}
@@ -0,0 +1,123 @@
+package vm
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/codecrafters-io/interpreter-starter-go/app/collection"
+)
+
+func isFalsey(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	default:
+		return false
+	}
+}
+
+func valuesEqual(a, b any) bool {
+	switch x := a.(type) {
+	case nil:
+		return b == nil
+	case float64:
+		y, ok := b.(float64)
+		return ok && x == y
+	case string:
+		y, ok := b.(string)
+		return ok && x == y
+	case bool:
+		y, ok := b.(bool)
+		return ok && x == y
+	default:
+		return a == b
+	}
+}
+
+// stringify formats values exactly like the tree-walking interpreter.
+func stringify(value any) string {
+	switch v := value.(type) {
+	case float64:
+		s := fmt.Sprintf("%f", v)
+		s = strings.TrimRight(s, "0")
+		s = strings.TrimRight(s, ".")
+		return s
+	case nil:
+		return "nil"
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func length(args []any) (any, error) {
+	switch v := args[0].(type) {
+	case *collection.List:
+		return float64(v.Len()), nil
+	case *collection.Map:
+		return float64(v.Len()), nil
+	case string:
+		return float64(utf8.RuneCountInString(v)), nil
+	}
+	return nil, fmt.Errorf("Can't take the length of a %s.", typeName(args[0]))
+}
+
+func typeName(value any) string {
+	switch value.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case *collection.List:
+		return "list"
+	case *collection.Map:
+		return "map"
+	case *Instance:
+		return "instance"
+	case *Class:
+		return "class"
+	case *Closure, *BoundMethod, *Native:
+		return "function"
+	default:
+		return "object"
+	}
+}
+
+// withRunes adapts a native written for the tree-walker's []rune strings,
+// such as a string method, to the VM's Go strings.
+func withRunes(fn NativeFn) NativeFn {
+	return func(args []any) (any, error) {
+		converted := make([]any, len(args))
+		for n, arg := range args {
+			if s, ok := arg.(string); ok {
+				converted[n] = []rune(s)
+			} else {
+				converted[n] = arg
+			}
+		}
+		result, e := fn(converted)
+		return fromRunes(result), e
+	}
+}
+
+// fromRunes converts a result of a []rune native. The lists string
+// methods return are new, so they are converted in place.
+func fromRunes(value any) any {
+	switch v := value.(type) {
+	case []rune:
+		return string(v)
+	case *collection.List:
+		for n, element := range v.Elements {
+			if runes, ok := element.([]rune); ok {
+				v.Elements[n] = string(runes)
+			}
+		}
+	}
+	return value
+}
@@ -0,0 +1,726 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/codecrafters-io/interpreter-starter-go/app/collection"
+	"github.com/codecrafters-io/interpreter-starter-go/app/compiler"
+	err "github.com/codecrafters-io/interpreter-starter-go/app/err"
+	"github.com/codecrafters-io/interpreter-starter-go/app/text"
+	tok "github.com/codecrafters-io/interpreter-starter-go/app/token"
+)
+
+const FramesMax = 4096
+
+type CallFrame struct {
+	closure *Closure
+	chunk   *compiler.Chunk
+	ip      int
+	slots   int
+}
+
+func (f *CallFrame) readByte() byte {
+	b := f.chunk.Code[f.ip]
+	f.ip++
+	return b
+}
+
+func (f *CallFrame) readShort() int {
+	f.ip += 2
+	return int(f.chunk.Code[f.ip-2])<<8 | int(f.chunk.Code[f.ip-1])
+}
+
+func (f *CallFrame) readString() string {
+	return f.chunk.Constants[f.readShort()].(string)
+}
+
+// tokenAt returns the source token of the instruction starting at offset.
+func (f *CallFrame) tokenAt(offset int) tok.Token {
+	t, ok := f.chunk.Token(offset)
+	if !ok {
+		t.Line = f.chunk.Line(offset)
+	}
+	return t
+}
+
+// handler is an installed try statement: where to jump when an error is
+// raised and how far to unwind the frames and stack first.
+type handler struct {
+	frame int
+	stack int
+	ip    int
+}
+
+// Loader finds and compiles the files scripts import.
+type Loader interface {
+	// Find returns the file that importing path from the module in file
+	// refers to.
+	Find(path string, from string) (string, error)
+	// Compile compiles a module's top-level code.
+	Compile(file string) (*compiler.Function, error)
+}
+
+type VM struct {
+	frames       []CallFrame
+	handlers     []handler
+	stack        []any
+	globals      map[string]any
+	openUpvalues *Upvalue
+	stdout       io.Writer
+	ctx          context.Context
+	steps        int
+	// main is the module of the scripts passed to Interpret, whose globals
+	// are globals. modules caches the modules imported so far.
+	main    *Module
+	modules map[string]*Module
+	loader  Loader
+}
+
+func New(stdout io.Writer) *VM {
+	vm := &VM{
+		frames:  make([]CallFrame, 0, 64),
+		stack:   make([]any, 0, 256),
+		globals: make(map[string]any),
+		modules: make(map[string]*Module),
+		stdout:  stdout,
+	}
+	vm.main = &Module{Globals: vm.globals}
+
+	vm.DefineNative("clock", 0, func([]any) (any, error) {
+		return float64(time.Now().Unix()), nil
+	})
+	vm.DefineNative("len", 1, length)
+	vm.DefineNative("fromCharCode", 1, withRunes(text.FromCharCode))
+	return vm
+}
+
+func (vm *VM) DefineNative(name string, arity int, fn NativeFn) {
+	vm.globals[name] = &Native{Name: name, Arity: arity, Fn: fn}
+}
+
+// SetLoader makes imports possible. file is where the scripts passed to
+// Interpret were read from, or empty.
+func (vm *VM) SetLoader(loader Loader, file string) {
+	vm.loader = loader
+	vm.main.File = file
+}
+
+// Interpret runs a compiled script and returns the script's result, which
+// is nil unless it was compiled with compiler.CompileEval. Runtime errors
+// are returned as *err.RuntimeError; cancelling ctx stops the VM with
+// ctx.Err(). Globals survive across calls.
+func (vm *VM) Interpret(ctx context.Context, function *compiler.Function) (any, error) {
+	vm.stack = vm.stack[:0]
+	vm.frames = vm.frames[:0]
+	vm.handlers = vm.handlers[:0]
+	vm.openUpvalues = nil
+	vm.ctx = ctx
+
+	closure := &Closure{Function: function, Module: vm.main}
+	vm.main.script = closure
+	vm.push(closure)
+	if e := vm.call(closure, 0, tok.Token{}); e != nil {
+		return nil, e
+	}
+	result, e := vm.run()
+	if e != nil {
+		vm.forget(0)
+	}
+	return result, e
+}
+
+// Globals returns a copy of the global variables.
+func (vm *VM) Globals() map[string]any {
+	globals := make(map[string]any, len(vm.globals))
+	for name, value := range vm.globals {
+		globals[name] = value
+	}
+	return globals
+}
+
+// interrupted polls the context on backward jumps and calls, which is
+// enough to stop any long-running script.
+func (vm *VM) interrupted() error {
+	vm.steps++
+	if vm.steps%1024 != 0 {
+		return nil
+	}
+	return vm.ctx.Err()
+}
+
+func (vm *VM) push(value any) {
+	vm.stack = append(vm.stack, value)
+}
+
+func (vm *VM) pop() any {
+	value := vm.stack[len(vm.stack)-1]
+	vm.stack = vm.stack[:len(vm.stack)-1]
+	return value
+}
+
+func (vm *VM) peek(distance int) any {
+	return vm.stack[len(vm.stack)-1-distance]
+}
+
+func (vm *VM) runtimeError(token tok.Token, format string, args ...any) error {
+	e := err.NewRuntimeError(token, fmt.Sprintf(format, args...))
+	e.Trace = vm.traceback(token.Line)
+	return e
+}
+
+// traceback describes the call stack, innermost first, for an error that
+// happened on line. The script's own frame at the bottom is left out.
+func (vm *VM) traceback(line int) []err.Frame {
+	trace := make([]err.Frame, 0, len(vm.frames))
+	for n := len(vm.frames) - 1; n >= 1; n-- {
+		trace = append(trace, err.Frame{Function: vm.frames[n].closure.Function.Name, Line: line})
+		caller := &vm.frames[n-1]
+		line = caller.chunk.Line(caller.ip - 1)
+	}
+	return trace
+}
+
+func (vm *VM) call(closure *Closure, argCount int, paren tok.Token) error {
+	if argCount != closure.Function.Arity {
+		return vm.runtimeError(paren, "Expected %d arguments but got %d.", closure.Function.Arity, argCount)
+	}
+
+	if len(vm.frames) == FramesMax {
+		return vm.runtimeError(paren, "Stack overflow.")
+	}
+
+	vm.frames = append(vm.frames, CallFrame{
+		closure: closure,
+		chunk:   &closure.Function.Chunk,
+		ip:      0,
+		slots:   len(vm.stack) - argCount - 1,
+	})
+	return nil
+}
+
+func (vm *VM) callValue(callee any, argCount int, paren tok.Token) error {
+	switch callee := callee.(type) {
+	case *BoundMethod:
+		vm.stack[len(vm.stack)-argCount-1] = callee.Receiver
+		return vm.call(callee.Method, argCount, paren)
+	case *Class:
+		vm.stack[len(vm.stack)-argCount-1] = &Instance{Class: callee, Fields: make(map[string]any)}
+		if initializer, ok := callee.Methods["init"]; ok {
+			return vm.call(initializer, argCount, paren)
+		}
+		if argCount != 0 {
+			return vm.runtimeError(paren, "Expected 0 arguments but got %d.", argCount)
+		}
+		return nil
+	case *Closure:
+		return vm.call(callee, argCount, paren)
+	case *Native:
+		if callee.Arity >= 0 && argCount != callee.Arity {
+			return vm.runtimeError(paren, "Expected %d arguments but got %d.", callee.Arity, argCount)
+		}
+		args := vm.stack[len(vm.stack)-argCount:]
+		result, e := callee.Fn(args)
+		if e != nil {
+			var runtimeError *err.RuntimeError
+			if errors.As(e, &runtimeError) {
+				if runtimeError.Trace == nil {
+					runtimeError.Trace = vm.traceback(runtimeError.Line())
+				}
+				return runtimeError
+			}
+			return vm.runtimeError(paren, "%s", e.Error())
+		}
+		vm.stack = vm.stack[:len(vm.stack)-argCount-1]
+		vm.push(result)
+		return nil
+	}
+	return vm.runtimeError(paren, "Can only call functions and classes.")
+}
+
+func (vm *VM) captureUpvalue(location int) *Upvalue {
+	var prev *Upvalue
+	upvalue := vm.openUpvalues
+	for upvalue != nil && upvalue.location > location {
+		prev = upvalue
+		upvalue = upvalue.next
+	}
+
+	if upvalue != nil && upvalue.location == location {
+		return upvalue
+	}
+
+	created := &Upvalue{location: location, next: upvalue}
+	if prev == nil {
+		vm.openUpvalues = created
+	} else {
+		prev.next = created
+	}
+	return created
+}
+
+func (vm *VM) closeUpvalues(last int) {
+	for vm.openUpvalues != nil && vm.openUpvalues.location >= last {
+		upvalue := vm.openUpvalues
+		upvalue.closed = vm.stack[upvalue.location]
+		upvalue.location = -1
+		vm.openUpvalues = upvalue.next
+	}
+}
+
+func (vm *VM) getUpvalue(upvalue *Upvalue) any {
+	if upvalue.location >= 0 {
+		return vm.stack[upvalue.location]
+	}
+	return upvalue.closed
+}
+
+func (vm *VM) setUpvalue(upvalue *Upvalue, value any) {
+	if upvalue.location >= 0 {
+		vm.stack[upvalue.location] = value
+		return
+	}
+	upvalue.closed = value
+}
+
+// importModule pushes the module that path names. A module that has not
+// run yet is called like a function, with the module in slot zero, and
+// its code returns it.
+func (vm *VM) importModule(path string, token tok.Token) error {
+	if vm.loader == nil {
+		return vm.runtimeError(token, "Can't find module '%s'.", path)
+	}
+	file, e := vm.loader.Find(path, vm.frames[len(vm.frames)-1].closure.Module.File)
+	if e != nil {
+		return vm.runtimeError(token, "%s", e.Error())
+	}
+
+	loading := make([]string, 0)
+	for _, frame := range vm.frames {
+		if module := frame.closure.Module; frame.closure == module.script {
+			loading = append(loading, module.File)
+		}
+	}
+	for n, f := range loading {
+		if f == file {
+			return vm.runtimeError(token, "%s", importCycle(append(loading[n:], file)))
+		}
+	}
+
+	if module, ok := vm.modules[file]; ok {
+		vm.push(module)
+		return nil
+	}
+
+	function, e := vm.loader.Compile(file)
+	if e != nil {
+		return vm.runtimeError(token, "%s", e.Error())
+	}
+	module := &Module{
+		Name:     strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)),
+		File:     file,
+		Globals:  make(map[string]any),
+		builtins: make(map[string]any),
+	}
+	for name, value := range vm.globals {
+		if native, ok := value.(*Native); ok {
+			module.Globals[name] = native
+			module.builtins[name] = native
+		}
+	}
+	module.script = &Closure{Function: function, Module: module}
+	vm.modules[file] = module
+	vm.push(module)
+	return vm.call(module.script, 0, token)
+}
+
+// forget drops the modules whose top-level code is running in frames n
+// and up, so that importing one again after an error runs it again. Imports
+// are never inside a try statement, so only errors that end the script
+// leave modules unfinished.
+func (vm *VM) forget(n int) {
+	for _, frame := range vm.frames[n:] {
+		if module := frame.closure.Module; frame.closure == module.script {
+			delete(vm.modules, module.File)
+		}
+	}
+}
+
+func (vm *VM) bindMethod(class *Class, name tok.Token) error {
+	method, ok := class.Methods[string(name.Lexeme)]
+	if !ok {
+		return vm.runtimeError(name, "Undefined property '%s'.", string(name.Lexeme))
+	}
+
+	vm.stack[len(vm.stack)-1] = &BoundMethod{Receiver: vm.peek(0), Method: method}
+	return nil
+}
+
+// run executes until the script returns, sending runtime errors to the
+// innermost try statement that is still installed.
+func (vm *VM) run() (any, error) {
+	for {
+		result, e := vm.execute()
+		var runtimeError *err.RuntimeError
+		if e == nil || len(vm.handlers) == 0 || !errors.As(e, &runtimeError) {
+			return result, e
+		}
+		vm.catch(runtimeError)
+	}
+}
+
+// catch unwinds to the innermost handler and resumes at its code with the
+// error on top of the stack.
+func (vm *VM) catch(e *err.RuntimeError) {
+	h := vm.handlers[len(vm.handlers)-1]
+	vm.handlers = vm.handlers[:len(vm.handlers)-1]
+
+	vm.frames = vm.frames[:h.frame+1]
+	vm.closeUpvalues(h.stack)
+	vm.stack = vm.stack[:h.stack]
+	vm.push(e)
+	vm.frames[h.frame].ip = h.ip
+}
+
+func (vm *VM) execute() (any, error) {
+	frame := &vm.frames[len(vm.frames)-1]
+
+	for {
+		start := frame.ip
+
+		switch op := compiler.OpCode(frame.readByte()); op {
+		case compiler.OP_CONSTANT:
+			vm.push(frame.chunk.Constants[frame.readShort()])
+		case compiler.OP_NIL:
+			vm.push(nil)
+		case compiler.OP_TRUE:
+			vm.push(true)
+		case compiler.OP_FALSE:
+			vm.push(false)
+		case compiler.OP_POP:
+			vm.pop()
+
+		case compiler.OP_GET_LOCAL:
+			vm.push(vm.stack[frame.slots+frame.readShort()])
+		case compiler.OP_SET_LOCAL:
+			vm.stack[frame.slots+frame.readShort()] = vm.peek(0)
+
+		case compiler.OP_GET_GLOBAL:
+			name := frame.readString()
+			value, ok := frame.closure.Module.Globals[name]
+			if !ok {
+				return nil, vm.runtimeError(frame.tokenAt(start), "Undefined variable '%s'.", name)
+			}
+			vm.push(value)
+		case compiler.OP_DEFINE_GLOBAL:
+			frame.closure.Module.Globals[frame.readString()] = vm.pop()
+		case compiler.OP_SET_GLOBAL:
+			name := frame.readString()
+			globals := frame.closure.Module.Globals
+			if _, ok := globals[name]; !ok {
+				return nil, vm.runtimeError(frame.tokenAt(start), "Undefined variable '%s'.", name)
+			}
+			globals[name] = vm.peek(0)
+
+		case compiler.OP_GET_UPVALUE:
+			vm.push(vm.getUpvalue(frame.closure.Upvalues[frame.readShort()]))
+		case compiler.OP_SET_UPVALUE:
+			vm.setUpvalue(frame.closure.Upvalues[frame.readShort()], vm.peek(0))
+
+		case compiler.OP_GET_PROPERTY:
+			name := frame.readString()
+			var method collection.Method
+			var found, builtin bool
+			switch object := vm.peek(0).(type) {
+			case *collection.List:
+				method, found = object.Method(name)
+				builtin = true
+			case *collection.Map:
+				method, found = object.Method(name)
+				builtin = true
+			case string:
+				method, found = text.Method([]rune(object), name)
+				method.Fn = withRunes(method.Fn)
+				builtin = true
+			}
+			if builtin {
+				if !found {
+					return nil, vm.runtimeError(frame.tokenAt(start), "Undefined property '%s'.", name)
+				}
+				vm.stack[len(vm.stack)-1] = &Native{Name: method.Name, Arity: method.Arity, Fn: method.Fn}
+				break
+			}
+
+			if module, ok := vm.peek(0).(*Module); ok {
+				value, found := module.Export(name)
+				if !found {
+					return nil, vm.runtimeError(frame.tokenAt(start), "Module '%s' has no export '%s'.", module.Name, name)
+				}
+				vm.stack[len(vm.stack)-1] = value
+				break
+			}
+
+			instance, ok := vm.peek(0).(*Instance)
+			if !ok {
+				return nil, vm.runtimeError(frame.tokenAt(start), "Only instances have properties.")
+			}
+			if value, ok := instance.Fields[name]; ok {
+				vm.stack[len(vm.stack)-1] = value
+				break
+			}
+			if e := vm.bindMethod(instance.Class, frame.tokenAt(start)); e != nil {
+				return nil, e
+			}
+		case compiler.OP_SET_PROPERTY:
+			name := frame.readString()
+			instance, ok := vm.peek(1).(*Instance)
+			if !ok {
+				return nil, vm.runtimeError(frame.tokenAt(start), "Only instances have fields.")
+			}
+			value := vm.pop()
+			instance.Fields[name] = value
+			vm.stack[len(vm.stack)-1] = value
+		case compiler.OP_GET_SUPER:
+			frame.readShort()
+			superclass := vm.pop().(*Class)
+			if e := vm.bindMethod(superclass, frame.tokenAt(start)); e != nil {
+				return nil, e
+			}
+
+		case compiler.OP_EQUAL:
+			b := vm.pop()
+			a := vm.pop()
+			vm.push(valuesEqual(a, b))
+
+		case compiler.OP_GREATER, compiler.OP_GREATER_EQUAL, compiler.OP_LESS, compiler.OP_LESS_EQUAL,
+			compiler.OP_SUBTRACT, compiler.OP_MULTIPLY, compiler.OP_DIVIDE:
+			b, bok := vm.peek(0).(float64)
+			a, aok := vm.peek(1).(float64)
+			if !aok || !bok {
+				return nil, vm.runtimeError(frame.tokenAt(start), "Operands must be numbers.")
+			}
+			vm.stack = vm.stack[:len(vm.stack)-1]
+
+			var result any
+			switch op {
+			case compiler.OP_GREATER:
+				result = a > b
+			case compiler.OP_GREATER_EQUAL:
+				result = a >= b
+			case compiler.OP_LESS:
+				result = a < b
+			case compiler.OP_LESS_EQUAL:
+				result = a <= b
+			case compiler.OP_SUBTRACT:
+				result = a - b
+			case compiler.OP_MULTIPLY:
+				result = a * b
+			case compiler.OP_DIVIDE:
+				result = a / b
+			}
+			vm.stack[len(vm.stack)-1] = result
+
+		case compiler.OP_ADD:
+			switch a := vm.peek(1).(type) {
+			case float64:
+				if b, ok := vm.peek(0).(float64); ok {
+					vm.stack = vm.stack[:len(vm.stack)-1]
+					vm.stack[len(vm.stack)-1] = a + b
+					continue
+				}
+			case string:
+				if b, ok := vm.peek(0).(string); ok {
+					vm.stack = vm.stack[:len(vm.stack)-1]
+					vm.stack[len(vm.stack)-1] = a + b
+					continue
+				}
+			}
+			return nil, vm.runtimeError(frame.tokenAt(start), "Operands must be two numbers or two strings.")
+
+		case compiler.OP_NOT:
+			vm.stack[len(vm.stack)-1] = isFalsey(vm.peek(0))
+		case compiler.OP_NEGATE:
+			value, ok := vm.peek(0).(float64)
+			if !ok {
+				return nil, vm.runtimeError(frame.tokenAt(start), "Operand must be a number.")
+			}
+			vm.stack[len(vm.stack)-1] = -value
+
+		case compiler.OP_PRINT:
+			fmt.Fprintln(vm.stdout, stringify(vm.pop()))
+
+		case compiler.OP_JUMP:
+			offset := frame.readShort()
+			frame.ip += offset
+		case compiler.OP_JUMP_IF_FALSE:
+			offset := frame.readShort()
+			if isFalsey(vm.peek(0)) {
+				frame.ip += offset
+			}
+		case compiler.OP_LOOP:
+			offset := frame.readShort()
+			frame.ip -= offset
+			if e := vm.interrupted(); e != nil {
+				return nil, e
+			}
+
+		case compiler.OP_CALL:
+			argCount := int(frame.readByte())
+			if e := vm.interrupted(); e != nil {
+				return nil, e
+			}
+			if e := vm.callValue(vm.peek(argCount), argCount, frame.tokenAt(start)); e != nil {
+				return nil, e
+			}
+			frame = &vm.frames[len(vm.frames)-1]
+
+		case compiler.OP_CLOSURE:
+			function := frame.chunk.Constants[frame.readShort()].(*compiler.Function)
+			closure := &Closure{
+				Function: function,
+				Upvalues: make([]*Upvalue, function.UpvalueCount),
+				Module:   frame.closure.Module,
+			}
+			for i := range closure.Upvalues {
+				isLocal := frame.readByte()
+				index := frame.readShort()
+				if isLocal == 1 {
+					closure.Upvalues[i] = vm.captureUpvalue(frame.slots + index)
+				} else {
+					closure.Upvalues[i] = frame.closure.Upvalues[index]
+				}
+			}
+			vm.push(closure)
+		case compiler.OP_CLOSE_UPVALUE:
+			vm.closeUpvalues(len(vm.stack) - 1)
+			vm.pop()
+
+		case compiler.OP_RETURN:
+			result := vm.pop()
+			vm.closeUpvalues(frame.slots)
+			vm.stack = vm.stack[:frame.slots]
+			vm.frames = vm.frames[:len(vm.frames)-1]
+			if len(vm.frames) == 0 {
+				return result, nil
+			}
+
+			vm.push(result)
+			frame = &vm.frames[len(vm.frames)-1]
+
+		case compiler.OP_CLASS:
+			vm.push(&Class{Name: frame.readString(), Methods: make(map[string]*Closure)})
+		case compiler.OP_INHERIT:
+			superclass, ok := vm.peek(1).(*Class)
+			if !ok {
+				return nil, vm.runtimeError(frame.tokenAt(start), "Superclass must be a class.")
+			}
+			subclass := vm.peek(0).(*Class)
+			for name, method := range superclass.Methods {
+				subclass.Methods[name] = method
+			}
+			vm.pop()
+		case compiler.OP_METHOD:
+			name := frame.readString()
+			method := vm.peek(0).(*Closure)
+			class := vm.peek(1).(*Class)
+			class.Methods[name] = method
+			vm.pop()
+
+		case compiler.OP_BUILD_LIST:
+			count := frame.readShort()
+			elements := make([]any, count)
+			copy(elements, vm.stack[len(vm.stack)-count:])
+			vm.stack = vm.stack[:len(vm.stack)-count]
+			vm.push(collection.NewList(elements))
+		case compiler.OP_BUILD_MAP:
+			count := frame.readShort()
+			m := collection.NewMap()
+			entries := vm.stack[len(vm.stack)-2*count:]
+			for n := 0; n < len(entries); n += 2 {
+				m.Set(entries[n], entries[n+1])
+			}
+			vm.stack = vm.stack[:len(vm.stack)-2*count]
+			vm.push(m)
+		case compiler.OP_GET_INDEX:
+			var value any
+			var e error
+			switch object := vm.peek(1).(type) {
+			case *collection.List:
+				value, e = object.Get(vm.peek(0))
+			case *collection.Map:
+				value, e = object.Get(vm.peek(0))
+			case string:
+				var char []rune
+				char, e = text.Index([]rune(object), vm.peek(0))
+				value = string(char)
+			default:
+				return nil, vm.runtimeError(frame.tokenAt(start), "Only lists, maps and strings can be indexed.")
+			}
+			if e != nil {
+				return nil, vm.runtimeError(frame.tokenAt(start), "%s", e.Error())
+			}
+			vm.stack = vm.stack[:len(vm.stack)-1]
+			vm.stack[len(vm.stack)-1] = value
+		case compiler.OP_SET_INDEX:
+			value := vm.peek(0)
+			switch object := vm.peek(2).(type) {
+			case *collection.List:
+				if e := object.Set(vm.peek(1), value); e != nil {
+					return nil, vm.runtimeError(frame.tokenAt(start), "%s", e.Error())
+				}
+			case *collection.Map:
+				object.Set(vm.peek(1), value)
+			case string:
+				return nil, vm.runtimeError(frame.tokenAt(start), "Strings can't be modified.")
+			default:
+				return nil, vm.runtimeError(frame.tokenAt(start), "Only lists and maps can be indexed.")
+			}
+			vm.stack = vm.stack[:len(vm.stack)-2]
+			vm.stack[len(vm.stack)-1] = value
+
+		case compiler.OP_TRY:
+			offset := frame.readShort()
+			vm.handlers = append(vm.handlers, handler{
+				frame: len(vm.frames) - 1,
+				stack: len(vm.stack),
+				ip:    frame.ip + offset,
+			})
+		case compiler.OP_END_TRY:
+			vm.handlers = vm.handlers[:len(vm.handlers)-1]
+		case compiler.OP_CATCH:
+			vm.stack[len(vm.stack)-1] = errorValue(vm.peek(0).(*err.RuntimeError))
+		case compiler.OP_THROW:
+			value := vm.pop()
+			// A finally block raising its error again.
+			if raised, ok := value.(*err.RuntimeError); ok {
+				return nil, raised
+			}
+			token := frame.tokenAt(start)
+			e := err.NewThrownError(token, thrownMessage(value), value)
+			e.Trace = vm.traceback(token.Line)
+			return nil, e
+
+		case compiler.OP_IMPORT:
+			if e := vm.importModule(frame.readString(), frame.tokenAt(start)); e != nil {
+				return nil, e
+			}
+			frame = &vm.frames[len(vm.frames)-1]
+		case compiler.OP_GET_EXPORT:
+			name := frame.readString()
+			module := vm.peek(0).(*Module)
+			value, ok := module.Export(name)
+			if !ok {
+				return nil, vm.runtimeError(frame.tokenAt(start), "Module '%s' has no export '%s'.", module.Name, name)
+			}
+			vm.push(value)
+
+		default:
+			return nil, vm.runtimeError(frame.tokenAt(start), "Unknown opcode %d.", op)
+		}
+	}
+}